@@ -0,0 +1,53 @@
+// Command ambiguity lists pokemon (including forms) whose silhouettes are near-identical.
+// With -json it writes the groups for embedding (internal/poke/ambiguity.json), so the server never has to
+// download every artwork to find them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/poke"
+)
+
+func main() {
+	regions := flag.String("regions", "", "comma separated region keys (default: all)")
+	minIoU := flag.Float64("iou", poke.DefaultAmbiguityIoU, "minimum mask IoU to group silhouettes")
+	forms := flag.Bool("forms", true, "include non-default varieties")
+	out := flag.String("json", "", "write the groups as JSON to this file (e.g. internal/poke/ambiguity.json)")
+	flag.Parse()
+
+	var selected []string
+	for _, k := range strings.Split(*regions, ",") {
+		if k != "" {
+			selected = append(selected, k)
+		}
+	}
+
+	client := poke.NewClient(time.Hour)
+	groups, failed := client.WarmAmbiguity(client.PokemonIDs(selected, *forms), *minIoU)
+	for _, id := range failed {
+		log.Printf("artwork %d: not available", id)
+	}
+
+	if *out != "" {
+		if err := poke.WriteAmbiguityGroups(*out, groups); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, g := range groups {
+		labels := make([]string, 0, len(g))
+		for _, id := range g {
+			name := "?"
+			if p, err := client.GetPokemon(id); err == nil {
+				name = p.Name
+			}
+			labels = append(labels, fmt.Sprintf("%d:%s", id, name))
+		}
+		fmt.Println(strings.Join(labels, "\t"))
+	}
+}
@@ -0,0 +1,38 @@
+// Command difficulty recomputes per-pokemon difficulty ratings from the session history in a bolt database
+// (SESSION_STORE=bolt). Stop the server first: bbolt allows one process per file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+func main() {
+	path := flag.String("db", "sessions.db", "bolt database written by the server")
+	top := flag.Int("top", 20, "print the N hardest pokemon (0 = all)")
+	dry := flag.Bool("dry-run", false, "print the ratings without storing them")
+	flag.Parse()
+
+	db, err := quiz.OpenBoltStore(*path, 0)
+	if err != nil {
+		log.Fatalf("open %s: %v", *path, err)
+	}
+	defer db.Close()
+
+	stats := quiz.Aggregate(db)
+	if !*dry {
+		db.ReplacePokemonStats(stats)
+	}
+
+	fmt.Printf("%d pokemon rated\n", len(stats))
+	fmt.Printf("%6s %10s %6s %7s %7s %9s\n", "id", "difficulty", "plays", "solved", "gaveup", "avgsolve")
+	for i, st := range stats {
+		if *top > 0 && i == *top {
+			break
+		}
+		fmt.Printf("%6d %10.3f %6d %6.0f%% %6.0f%% %8.1fs\n", st.PokemonID, st.Difficulty, st.Plays, st.SolveRate*100, st.GiveUpRate*100, float64(st.AvgSolveMs)/1000)
+	}
+}
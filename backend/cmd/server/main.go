@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/base64"
 	"log"
 	"net/http"
 	"os"
@@ -25,10 +26,80 @@ func main() {
 		MaxAge:           300,
 	}))
 
+	// optional font overriding the embedded ones on result cards
+	if path := os.Getenv("CARD_FONT"); path != "" {
+		if err := poke.LoadCardFont(path); err != nil {
+			log.Printf("card font: %v", err)
+		}
+	}
+	if !poke.HasJapaneseFont() {
+		log.Printf("no Japanese card font (run internal/poke/fonts/subset.sh or set CARD_FONT); result cards use English")
+	}
+
 	// dependencies
 	client := poke.NewClient(30 * time.Minute)
-	store := quiz.NewStore()
-	h := ih.NewHandlers(client, store)
+	if !client.HasAmbiguityGroups() && os.Getenv("SESSION_STORE") != "token" {
+		// no embedded groups (see cmd/ambiguity -json): use the ones found on an earlier boot, or find them in the
+		// background and keep them for the next; until then quizzes asking for ambiguity handling are refused.
+		// Serverless (token) instances are too short-lived for this and need the embedded groups.
+		cache := os.Getenv("AMBIGUITY_FILE")
+		if cache == "" {
+			cache = "ambiguity.json"
+		}
+		if err := client.LoadAmbiguityGroups(cache); err == nil {
+			log.Printf("ambiguity: groups loaded from %s", cache)
+		} else {
+			go func() {
+				groups, failed := client.WarmAmbiguity(client.PokemonIDs(nil, true), poke.DefaultAmbiguityIoU)
+				// some forms have no official artwork at all, so failures are kept too; delete the file to recompute
+				log.Printf("ambiguity: %d groups (%d artworks unavailable), saved to %s", len(groups), len(failed), cache)
+				if err := poke.WriteAmbiguityGroups(cache, groups); err != nil {
+					log.Printf("ambiguity: %v", err)
+				}
+			}()
+		}
+	}
+	var store quiz.SessionStore
+	var daily quiz.DailyBook = quiz.NewMemoryDailyBook()
+	var history quiz.History = quiz.NewMemoryHistory(100000)
+	var difficulty quiz.DifficultyStore = quiz.NewMemoryDifficulty()
+	var ratings quiz.RatingStore = quiz.NewMemoryRatings()
+	switch os.Getenv("SESSION_STORE") {
+	case "", "memory":
+		mem := quiz.NewMemoryStore(2*time.Hour, 10000)
+		mem.StartJanitor(time.Minute)
+		store = mem
+	case "bolt":
+		path := os.Getenv("SESSION_DB")
+		if path == "" {
+			path = "sessions.db"
+		}
+		db, err := quiz.OpenBoltStore(path, 2*time.Hour)
+		if err != nil {
+			log.Fatalf("session store: %v", err)
+		}
+		db.StartJanitor(time.Minute)
+		store = db
+		daily = db
+		history = db
+		difficulty = db
+		ratings = db
+	case "token":
+		// every instance must share the key, or each would reject the others' tokens
+		key, err := base64.StdEncoding.DecodeString(os.Getenv("SESSION_TOKEN_KEY"))
+		if err != nil || len(key) == 0 {
+			log.Fatalf("SESSION_STORE=token requires SESSION_TOKEN_KEY (base64 of 32 random bytes)")
+		}
+		ts, err := quiz.NewTokenStore(key, 2*time.Hour)
+		if err != nil {
+			log.Fatalf("session store: %v", err)
+		}
+		store = ts
+		log.Printf("SESSION_STORE=token: tokens can be replayed, so play is unranked (no history, ratings or daily challenge)")
+	default:
+		log.Fatalf("unknown SESSION_STORE %q (memory, bolt or token)", os.Getenv("SESSION_STORE"))
+	}
+	h := ih.NewHandlers(client, store, daily, history, difficulty, ratings)
 
 	h.Register(r)
 
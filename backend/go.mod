@@ -5,4 +5,11 @@ go 1.25.0
 require (
 	github.com/go-chi/chi/v5 v5.0.12
 	github.com/go-chi/cors v1.2.1
+	go.etcd.io/bbolt v1.4.3
+	golang.org/x/image v0.25.0
+)
+
+require (
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 )
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/poke"
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+var errNoRange = errors.New("no pokemon range selected")
+var errNoCandidates = errors.New("no candidates available")
+var errNoHardCandidates = errors.New("no candidates are rated as hard as minDifficulty")
+
+// candidate is one pickable pokemon: a species' default form or an allowed variety
+type candidate struct {
+	id      int
+	species int // national dex id; equals id for default forms
+	name    string
+	jp      string
+	types   []string
+}
+
+// poolSettings are the start options that decide which pokemon can be picked
+type poolSettings struct {
+	Regions       []string
+	AllowMega     bool
+	AllowPrimal   bool
+	Ambiguity     string
+	MinDifficulty float64
+}
+
+// pool is the candidate set for a quiz's settings
+type pool struct {
+	poolSettings
+	candidates []candidate
+	groupOf    map[int][]int // ambiguity group of each grouped candidate id
+	partial    bool          // some pokemon were skipped because PokeAPI did not return them
+}
+
+// settingsOf returns the pool settings a session was started with
+func settingsOf(sess *quiz.Session) poolSettings {
+	return poolSettings{Regions: sess.Regions, AllowMega: sess.AllowMega, AllowPrimal: sess.AllowPrimal, Ambiguity: sess.Ambiguity, MinDifficulty: sess.MinDifficulty}
+}
+
+// buildPool collects the candidates for ps (PokeAPI responses are cached by the client)
+func (h *Handlers) buildPool(ps poolSettings) (*pool, error) {
+	baseIDs := make([]int, 0)
+	selected := map[string]bool{}
+	for _, rk := range ps.Regions {
+		selected[rk] = true
+	}
+
+	allSelected := len(selected) == 0
+	for _, rg := range poke.Regions {
+		if allSelected || selected[rg.Key] {
+			for id := rg.From; id <= rg.To; id++ {
+				baseIDs = append(baseIDs, id)
+			}
+		}
+	}
+
+	if len(baseIDs) == 0 {
+		return nil, errNoRange
+	}
+
+	// Collect candidate forms (store as struct with PokemonID + display overrides)
+	candidates := make([]candidate, 0, len(baseIDs))
+	partial := false
+	for _, id := range baseIDs {
+		base, err := h.baseCandidate(id)
+		if err != nil {
+			partial = true
+			continue
+		}
+		jpName := base.jp
+		candidates = append(candidates, base)
+
+		// optional: forms (mega/primal) if allowed (and later filtered by region rules)
+		if ps.AllowMega || ps.AllowPrimal {
+			sp, err := h.poke.GetSpecies(id)
+			if err != nil {
+				partial = true
+				continue
+			}
+			for _, v := range sp.Varieties {
+				// Skip default (base) already added
+				if v.IsDefault {
+					continue
+				}
+
+				n := v.Pokemon.Name // e.g. "charizard-mega-x"
+				lower := strings.ToLower(n)
+				isMega := strings.Contains(lower, "mega")
+				isPrimal := strings.Contains(lower, "primal") || strings.Contains(lower, "groudon-primal") || strings.Contains(lower, "kyogre-primal")
+				if (isMega && !ps.AllowMega) || (isPrimal && !ps.AllowPrimal) {
+					continue
+				}
+				// Regional form filter (alola/galar/hisui/paldea) -> これらは選択された地方に含まれていない場合除外
+				// 名前に "-alola", "-galar", "-hisui", "-paldea" 等を含む場合に該当
+				regionalTag := ""
+				if strings.Contains(lower, "-alola") {
+					regionalTag = "alola"
+				}
+				if strings.Contains(lower, "-galar") {
+					regionalTag = "galar"
+				}
+				if strings.Contains(lower, "-hisui") {
+					regionalTag = "hisui"
+				} // Hisui -> 現在 Regions に hisui は無いが将来的拡張考慮
+				if strings.Contains(lower, "-paldea") {
+					regionalTag = "paldea"
+				}
+				if regionalTag != "" {
+					// Regions に存在しないタグ (hisui) は、現在選択対象にない限り除外（hisui 未サポートのためデフォ除外）
+					if !allSelected { // 全選択であれば残す
+						if _, ok := selected[regionalTag]; !ok {
+							continue
+						}
+					}
+				}
+				// Extract id from pokemon URL (ends with /pokemon/{id}/)
+				formID, err := poke.IDFromURL(v.Pokemon.URL)
+				if err != nil {
+					continue
+				}
+				fp, err := h.poke.GetPokemon(formID)
+				if err != nil {
+					partial = true
+					continue
+				}
+				fTypes := make([]string, 0, len(fp.Types))
+				for _, t := range fp.Types {
+					fTypes = append(fTypes, t.Type.Name)
+				}
+				// Japanese name for form: fallback to base JP if specific not provided (species names are species-level)
+				// For now we use base species JP so AcceptAnswers include both base JP and base EN; form-specific english kept.
+				candidates = append(candidates, candidate{id: formID, species: id, name: fp.Name, jp: jpName, types: fTypes})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errNoCandidates
+	}
+
+	// group candidates with near-identical silhouettes; groups are precomputed, so this never fetches artwork
+	groupOf := map[int][]int{}
+	if ps.Ambiguity != "" {
+		inPool := make(map[int]bool, len(candidates))
+		for _, c := range candidates {
+			inPool[c.id] = true
+		}
+		for _, c := range candidates {
+			g := make([]int, 0, 2)
+			for _, id := range h.poke.AmbiguityGroup(c.id) {
+				if inPool[id] {
+					g = append(g, id)
+				}
+			}
+			if len(g) > 1 {
+				groupOf[c.id] = g
+			}
+		}
+	}
+	if ps.Ambiguity == ambiguityExclude {
+		kept := candidates[:0]
+		for _, c := range candidates {
+			if g, ok := groupOf[c.id]; ok && g[0] != c.id {
+				continue
+			}
+			kept = append(kept, c)
+		}
+		candidates = kept
+	}
+
+	if ps.MinDifficulty > 0 {
+		kept := candidates[:0]
+		for _, c := range candidates {
+			if quiz.DifficultyOf(h.difficulty, c.id) >= ps.MinDifficulty {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == 0 {
+			return nil, errNoHardCandidates
+		}
+		candidates = kept
+	}
+
+	return &pool{poolSettings: ps, candidates: candidates, groupOf: groupOf, partial: partial}, nil
+}
+
+// baseCandidate returns the default form of species id
+func (h *Handlers) baseCandidate(id int) (candidate, error) {
+	p, err := h.poke.GetPokemon(id)
+	if err != nil {
+		return candidate{}, err
+	}
+	jpName, _ := h.poke.GetJapaneseName(id)
+	types := make([]string, 0, len(p.Types))
+	for _, t := range p.Types {
+		types = append(types, t.Type.Name)
+	}
+	return candidate{id: id, species: id, name: p.Name, jp: jpName, types: types}, nil
+}
+
+// excluding returns the candidates whose ids are not in used
+func (p *pool) excluding(used []int) []candidate {
+	skip := make(map[int]bool, len(used))
+	for _, id := range used {
+		skip[id] = true
+	}
+	out := make([]candidate, 0, len(p.candidates))
+	for _, c := range p.candidates {
+		if !skip[c.id] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// newSession creates a session asking for picked, with the answers the pool's settings accept
+func (p *pool) newSession(picked candidate) *quiz.Session {
+	// region determination uses national dex id (base id mapping); forms take their species' region
+	regionKey := regionOf(picked.species)
+
+	sess := quiz.NewSession(picked.id, picked.name, regionKey, picked.types, p.AllowMega, p.AllowPrimal)
+	sess.Regions = p.Regions
+	sess.Ambiguity = p.Ambiguity
+	sess.MinDifficulty = p.MinDifficulty
+	sess.Partial = p.partial
+	if picked.jp != "" {
+		sess.DisplayName = picked.jp
+		sess.AcceptAnswers = append(sess.AcceptAnswers, picked.jp)
+	}
+	// Accept base english name if form (strip suffix after last '-')
+	if strings.Contains(picked.name, "-") {
+		base := picked.name
+		if idx := strings.Index(base, "-mega"); idx > 0 {
+			base = base[:idx]
+		}
+		if idx := strings.Index(base, "-primal"); idx > 0 {
+			base = base[:idx]
+		}
+		if idx := strings.Index(base, "-gmax"); idx > 0 {
+			base = base[:idx]
+		}
+
+		if base != picked.name {
+			sess.AcceptAnswers = append(sess.AcceptAnswers, base)
+		}
+	}
+	if p.Ambiguity == ambiguityAccept {
+		for _, id := range p.groupOf[picked.id] {
+			for _, c := range p.candidates {
+				if c.id == id && id != picked.id {
+					sess.AcceptAnswers = append(sess.AcceptAnswers, c.name)
+					if c.jp != "" {
+						sess.AcceptAnswers = append(sess.AcceptAnswers, c.jp)
+					}
+				}
+			}
+		}
+	}
+	return sess
+}
+
+// regionOf returns the region introducing national dex id (empty if none)
+func regionOf(id int) string {
+	for _, rg := range poke.Regions {
+		if rg.ContainsNationalID(id) {
+			return rg.Key
+		}
+	}
+	return ""
+}
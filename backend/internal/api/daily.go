@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	stdhttp "net/http"
+	"time"
+
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/poke"
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+// maxPlayerID bounds client-chosen player ids
+const maxPlayerID = 64
+
+type dailyStartRequest struct {
+	PlayerID string `json:"playerId"` // random id the client keeps (e.g. in localStorage)
+}
+type dailyStartResponse struct {
+	SessionID string `json:"sessionId"`
+	Date      string `json:"date"`
+}
+
+// startDaily starts today's challenge: the pokemon follows from the date alone, and each player gets one attempt.
+// Unranked deployments refuse it, since replayed tokens could finish an attempt again.
+func (h *Handlers) startDaily(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if !h.ranked {
+		httpError(w, 501, "the daily challenge needs a server-side session store (SESSION_STORE=memory or bolt)")
+		return
+	}
+	var req dailyStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, 400, err.Error())
+		return
+	}
+	if req.PlayerID == "" || len(req.PlayerID) > maxPlayerID {
+		httpError(w, 400, "playerId is required (at most 64 characters)")
+		return
+	}
+
+	date := quiz.DailyDate(time.Now())
+	c, err := h.baseCandidate(dailyPokemon(date))
+	if err != nil {
+		httpError(w, 502, err.Error())
+		return
+	}
+
+	sess := (&pool{}).newSession(c)
+	sess.Style = string(poke.StyleOfficial)
+	sess.Mode = quiz.ModeDaily
+	sess.Daily = date
+	sess.Seed = quiz.DailySeed(date)
+	sess.Difficulty = quiz.DifficultyOf(h.difficulty, c.id)
+	sess.PlayerID = req.PlayerID
+	// claim before storing, so a refused player never gets a session
+	if err := h.daily.Claim(date, req.PlayerID); err == quiz.ErrDailyPlayed {
+		httpError(w, 409, err.Error())
+		return
+	} else if err != nil {
+		httpError(w, 500, err.Error())
+		return
+	}
+
+	h.store.Set(sess)
+	writeJSON(w, dailyStartResponse{SessionID: sess.ID, Date: date})
+}
+
+// dailyPokemon picks the national dex id of date's challenge from a PRNG seeded by the date,
+// over every region, so all servers agree without shared state
+func dailyPokemon(date string) int {
+	ids := make([]int, 0)
+	for _, rg := range poke.Regions {
+		for id := rg.From; id <= rg.To; id++ {
+			ids = append(ids, id)
+		}
+	}
+	return quiz.RandomID(quiz.SeededRand(quiz.DailySeed(date), 0), ids)
+}
+
+// dailyStats reports how players did on ?date= (YYYY-MM-DD, default today)
+func (h *Handlers) dailyStats(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = quiz.DailyDate(time.Now())
+	} else if _, err := time.Parse(time.DateOnly, date); err != nil {
+		httpError(w, 400, "date must be YYYY-MM-DD")
+		return
+	}
+
+	writeJSON(w, h.daily.Stats(date))
+}
+
+// recordFinish books the outcome of a finished session in the history, pokemon stats and Elo ratings (and the daily
+// book for daily attempts); it is safe to call repeatedly, and books nothing when h is unranked
+func (h *Handlers) recordFinish(sess *quiz.Session) {
+	if !h.ranked || !sess.Finished() {
+		return
+	}
+	if rec := quiz.RecordOf(sess); h.history.Add(rec) {
+		h.difficulty.Observe(rec)
+		h.ratings.RateSession(rec)
+	}
+	if sess.Daily != "" {
+		h.daily.Finish(sess.Daily, sess.PlayerID, sess.Solved, sess.Guesses)
+	}
+}
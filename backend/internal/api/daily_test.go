@@ -0,0 +1,35 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/poke"
+)
+
+func TestDailyPokemonIsStablePerDate(t *testing.T) {
+	last := poke.Regions[len(poke.Regions)-1].To
+	seen := map[int]bool{}
+	for day := 1; day <= 28; day++ {
+		date := fmt.Sprintf("2026-02-%02d", day)
+		id := dailyPokemon(date)
+		for i := 0; i < 3; i++ {
+			if again := dailyPokemon(date); again != id {
+				t.Fatalf("%s: picked %d then %d", date, id, again)
+			}
+		}
+		if id < 1 || id > last {
+			t.Fatalf("%s: picked %d outside the national dex", date, id)
+		}
+		seen[id] = true
+	}
+	// every server and release must agree on past and future challenges
+	for date, want := range map[string]int{"2026-01-01": 788, "2026-10-19": 830} {
+		if got := dailyPokemon(date); got != want {
+			t.Errorf("%s: picked %d, want %d", date, got, want)
+		}
+	}
+	if len(seen) < 20 {
+		t.Errorf("28 dates picked only %d distinct pokemon", len(seen))
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	stdhttp "net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+// difficultyList returns rated pokemon hardest first; ?min= keeps ratings >= min, ?limit= caps the count
+func (h *Handlers) difficultyList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	minD := 0.0
+	if v := r.URL.Query().Get("min"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			httpError(w, 400, "min must be between 0 and 1")
+			return
+		}
+		minD = f
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			httpError(w, 400, "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+
+	out := []quiz.PokemonStats{}
+	for _, st := range h.difficulty.AllPokemonStats() {
+		if st.Difficulty < minD {
+			break // sorted hardest first
+		}
+		out = append(out, st)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	writeJSON(w, out)
+}
+
+// difficultyByID returns the stats of one pokemon; unplayed pokemon report DefaultDifficulty
+func (h *Handlers) difficultyByID(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "pokemonId"))
+	if err != nil || id <= 0 {
+		httpError(w, 400, "invalid pokemon id")
+		return
+	}
+	st, ok := h.difficulty.PokemonStats(id)
+	if !ok {
+		st = quiz.PokemonStats{PokemonID: id, Difficulty: quiz.DefaultDifficulty}
+	}
+	writeJSON(w, st)
+}
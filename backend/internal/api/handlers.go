@@ -1,9 +1,12 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"image/png"
-	"math/rand/v2"
+	"errors"
+	"fmt"
+	"image/color"
 	stdhttp "net/http"
 	"strconv"
 	"strings"
@@ -15,11 +18,25 @@ import (
 )
 
 type Handlers struct {
-	poke  *poke.Client
-	store *quiz.Store
+	poke       *poke.Client
+	store      quiz.SessionStore
+	daily      quiz.DailyBook
+	history    quiz.History
+	difficulty quiz.DifficultyStore
+	ratings    quiz.RatingStore
+	// ranked is false when the store can replay old session snapshots: outcomes are then neither scored nor
+	// booked in history, difficulty, ratings or the daily challenge
+	ranked bool
 }
 
-func NewHandlers(p *poke.Client, s *quiz.Store) *Handlers { return &Handlers{poke: p, store: s} }
+func NewHandlers(p *poke.Client, s quiz.SessionStore, d quiz.DailyBook, hist quiz.History, diff quiz.DifficultyStore, rt quiz.RatingStore) *Handlers {
+	h := &Handlers{poke: p, store: s, daily: d, history: hist, difficulty: diff, ratings: rt, ranked: !quiz.Replayable(s)}
+	// time-ups nobody asks about are booked when the store's janitor settles them
+	if ds, ok := s.(quiz.DeadlineSettler); ok {
+		ds.OnTimeUp(h.recordFinish)
+	}
+	return h
+}
 
 func (h *Handlers) Register(r chi.Router) {
 	r.Get("/health", func(w stdhttp.ResponseWriter, r *stdhttp.Request) { w.Write([]byte("ok")) })
@@ -28,15 +45,42 @@ func (h *Handlers) Register(r chi.Router) {
 	r.Post("/api/quiz/giveup", h.giveup)
 	r.Get("/api/quiz/silhouette/{sessionId}", h.silhouetteBySession)
 	r.Get("/api/quiz/artwork/{sessionId}", h.artworkBySession)
-	r.Get("/api/quiz/hint/{sessionId}", h.hintBySession)
+	r.Get("/api/quiz/reveal/{sessionId}", h.revealBySession)
+	r.Get("/api/quiz/result-card/{sessionId}", h.resultCardBySession)
+	r.Post("/api/quiz/hint", h.hint)
+	r.Get("/api/quiz/session/{sessionId}", h.sessionByID)
 	r.Get("/api/quiz/search", h.search)
+	r.Post("/api/quiz/daily/start", h.startDaily)
+	r.Get("/api/quiz/daily/stats", h.dailyStats)
+	r.Get("/api/quiz/difficulty", h.difficultyList)
+	r.Get("/api/quiz/difficulty/{pokemonId}", h.difficultyByID)
+	r.Get("/api/quiz/rating/{playerId}", h.playerRating)
 }
 
 type startRequest struct {
 	Regions     []string `json:"regions"`
 	AllowMega   bool     `json:"allowMega"`
 	AllowPrimal bool     `json:"allowPrimal"`
+	Ambiguity   string   `json:"ambiguity"` // "", "exclude" or "accept"
+	Style       string   `json:"style"`     // artwork style, see poke.ParseStyle
+	Cooldown    string   `json:"cooldown"`  // "normal" (5s), "practice" (none) or "escalating"
+	TimeLimit   int      `json:"timeLimit"` // time attack: seconds from start until the session times out (0 = none)
+	Mode        string   `json:"mode"`      // "" or "endless"
+	// Seed (decimal uint64, as returned in a result) replays the same pick with the same settings; empty picks randomly
+	Seed string `json:"seed"`
+	// Pick is the selection strategy: "uniform" (default), "species", "region", "difficulty", "missed" or "adaptive"
+	Pick     string `json:"pick"`
+	PlayerID string `json:"playerId"` // random id the client keeps; needed for pick=missed and pick=adaptive
+	// MinDifficulty keeps only pokemon rated at least this hard (0..1, e.g. quiz.HardDifficulty); 0 keeps all
+	MinDifficulty float64 `json:"minDifficulty"`
 }
+
+// Handling of candidates whose silhouettes are near-identical (e.g. cosmetic forms)
+const (
+	ambiguityExclude = "exclude" // keep only the lowest id of each group
+	ambiguityAccept  = "accept"  // any name in the picked candidate's group is correct
+)
+
 type startResponse struct {
 	SessionID string `json:"sessionId"`
 }
@@ -48,148 +92,91 @@ func (h *Handlers) startQuiz(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		return
 	}
 
-	baseIDs := make([]int, 0)
-	selected := map[string]bool{}
-	for _, rk := range req.Regions {
-		selected[rk] = true
+	if req.Ambiguity != "" && req.Ambiguity != ambiguityExclude && req.Ambiguity != ambiguityAccept {
+		httpError(w, 400, "ambiguity must be exclude or accept")
+		return
 	}
-
-	allSelected := len(selected) == 0
-	for _, rg := range poke.Regions {
-		if allSelected || selected[rg.Key] {
-			for id := rg.From; id <= rg.To; id++ {
-				baseIDs = append(baseIDs, id)
-			}
-		}
+	style, err := poke.ParseStyle(req.Style)
+	if err != nil {
+		httpError(w, 400, err.Error())
+		return
 	}
-
-	if len(baseIDs) == 0 {
-		httpError(w, 400, "no pokemon range selected")
+	cooldown, ok := quiz.CooldownPreset(req.Cooldown)
+	if !ok {
+		httpError(w, 400, "cooldown must be normal, practice or escalating")
 		return
 	}
-
-	// Collect candidate forms (store as struct with PokemonID + display overrides)
-	type cand struct {
-		id    int
-		name  string
-		jp    string
-		types []string
+	timeLimit := time.Duration(req.TimeLimit) * time.Second
+	if timeLimit != 0 && (timeLimit < quiz.MinTimeLimit || timeLimit > quiz.MaxTimeLimit) {
+		httpError(w, 400, fmt.Sprintf("timeLimit must be 0 or between %d and %d seconds", int(quiz.MinTimeLimit.Seconds()), int(quiz.MaxTimeLimit.Seconds())))
+		return
 	}
-	candidates := make([]cand, 0, len(baseIDs))
-	for _, id := range baseIDs {
-		p, err := h.poke.GetPokemon(id)
-		if err != nil {
-			continue
-		}
-		// base
-		jpName, _ := h.poke.GetJapaneseName(id)
-		types := make([]string, 0, len(p.Types))
-		for _, t := range p.Types {
-			types = append(types, t.Type.Name)
-		}
-		candidates = append(candidates, cand{id: id, name: p.Name, jp: jpName, types: types})
-
-		// optional: forms (mega/primal) if allowed (and later filtered by region rules)
-		if req.AllowMega || req.AllowPrimal {
-			sp, err := h.poke.GetSpecies(id)
-			if err != nil {
-				continue
-			}
-			for _, v := range sp.Varieties {
-				// Skip default (base) already added
-				if v.IsDefault {
-					continue
-				}
-
-				n := v.Pokemon.Name // e.g. "charizard-mega-x"
-				lower := strings.ToLower(n)
-				isMega := strings.Contains(lower, "mega")
-				isPrimal := strings.Contains(lower, "primal") || strings.Contains(lower, "groudon-primal") || strings.Contains(lower, "kyogre-primal")
-				if (isMega && !req.AllowMega) || (isPrimal && !req.AllowPrimal) {
-					continue
-				}
-				// Regional form filter (alola/galar/hisui/paldea) -> これらは選択された地方に含まれていない場合除外
-				// 名前に "-alola", "-galar", "-hisui", "-paldea" 等を含む場合に該当
-				regionalTag := ""
-				if strings.Contains(lower, "-alola") {
-					regionalTag = "alola"
-				}
-				if strings.Contains(lower, "-galar") {
-					regionalTag = "galar"
-				}
-				if strings.Contains(lower, "-hisui") {
-					regionalTag = "hisui"
-				} // Hisui -> 現在 Regions に hisui は無いが将来的拡張考慮
-				if strings.Contains(lower, "-paldea") {
-					regionalTag = "paldea"
-				}
-				if regionalTag != "" {
-					// Regions に存在しないタグ (hisui) は、現在選択対象にない限り除外（hisui 未サポートのためデフォ除外）
-					if !allSelected { // 全選択であれば残す
-						if _, ok := selected[regionalTag]; !ok {
-							continue
-						}
-					}
-				}
-				// Extract id from pokemon URL (ends with /pokemon/{id}/)
-				// URL pattern: https://pokeapi.co/api/v2/pokemon/{id}/
-				parts := strings.Split(strings.TrimSuffix(v.Pokemon.URL, "/"), "/")
-				if len(parts) < 1 {
-					continue
-				}
-				idStr := parts[len(parts)-1]
-				formID, err := strconv.Atoi(idStr)
-				if err != nil {
-					continue
-				}
-				fp, err := h.poke.GetPokemon(formID)
-				if err != nil {
-					continue
-				}
-				fTypes := make([]string, 0, len(fp.Types))
-				for _, t := range fp.Types {
-					fTypes = append(fTypes, t.Type.Name)
-				}
-				// Japanese name for form: fallback to base JP if specific not provided (species names are species-level)
-				// For now we use base species JP so AcceptAnswers include both base JP and base EN; form-specific english kept.
-				candidates = append(candidates, cand{id: formID, name: fp.Name, jp: jpName, types: fTypes})
-			}
-		}
+	if req.Mode != "" && req.Mode != quiz.ModeEndless {
+		httpError(w, 400, "mode must be endless or empty")
+		return
 	}
-	if len(candidates) == 0 {
-		httpError(w, 500, "no candidates available")
+	if len(req.PlayerID) > maxPlayerID {
+		httpError(w, 400, "playerId must be at most 64 characters")
 		return
 	}
-	picked := candidates[rand.IntN(len(candidates))]
-	// region determination uses national dex id (base id mapping). For forms, fallback to underlying species base id logic (approx: if larger id, still find by baseIDs inclusion).
-	regionKey := ""
-	for _, rg := range poke.Regions {
-		if rg.ContainsNationalID(picked.id) {
-			regionKey = rg.Key
-			break
-		}
+	if err := validPick(req.Pick, req.PlayerID); err != nil {
+		httpError(w, 400, err.Error())
+		return
 	}
-
-	sess := quiz.NewSession(picked.id, picked.name, regionKey, picked.types, req.AllowMega, req.AllowPrimal)
-	if picked.jp != "" {
-		sess.DisplayName = picked.jp
-		sess.AcceptAnswers = append(sess.AcceptAnswers, picked.jp)
+	if !h.ranked && (req.Pick == pickMissed || req.Pick == pickAdaptive) {
+		httpError(w, 400, errPickNeedsRanked.Error())
+		return
 	}
-	// Accept base english name if form (strip suffix after last '-')
-	if strings.Contains(picked.name, "-") {
-		base := picked.name
-		if idx := strings.Index(base, "-mega"); idx > 0 {
-			base = base[:idx]
-		}
-		if idx := strings.Index(base, "-primal"); idx > 0 {
-			base = base[:idx]
+	if req.MinDifficulty < 0 || req.MinDifficulty > 1 {
+		httpError(w, 400, "minDifficulty must be between 0 and 1")
+		return
+	}
+	if req.Ambiguity != "" && !h.poke.HasAmbiguityGroups() {
+		// the groups are still being computed, or this deployment has none (token mode without ambiguity.json)
+		httpError(w, 503, "ambiguity groups are not available yet")
+		return
+	}
+	seed := quiz.NewSeed()
+	if req.Seed != "" {
+		if seed, err = strconv.ParseUint(req.Seed, 10, 64); err != nil {
+			httpError(w, 400, "seed must be an unsigned 64-bit integer")
+			return
 		}
-		if idx := strings.Index(base, "-gmax"); idx > 0 {
-			base = base[:idx]
+		if !reproducible(req.Pick, req.MinDifficulty) {
+			httpError(w, 400, errSeedNotReproducible.Error())
+			return
 		}
+	}
 
-		if base != picked.name {
-			sess.AcceptAnswers = append(sess.AcceptAnswers, base)
+	p, err := h.buildPool(poolSettings{Regions: req.Regions, AllowMega: req.AllowMega, AllowPrimal: req.AllowPrimal, Ambiguity: req.Ambiguity, MinDifficulty: req.MinDifficulty})
+	if err == errNoRange || err == errNoHardCandidates {
+		httpError(w, 400, err.Error())
+		return
+	}
+	if err != nil {
+		httpError(w, 500, err.Error())
+		return
+	}
+	if req.Seed != "" && p.partial {
+		// a pool missing some pokemon would shift the pick away from the one the seed stands for
+		httpError(w, 503, "pokeapi is unavailable, so the seed cannot be replayed now")
+		return
+	}
+
+	sess := p.newSession(h.pickCandidate(quiz.SeededRand(seed, 0), p.candidates, req.Pick, req.PlayerID))
+	sess.Seed = seed
+	sess.Seeded = req.Seed != ""
+	sess.Difficulty = quiz.DifficultyOf(h.difficulty, sess.PokemonID)
+	sess.Pick = req.Pick
+	sess.PlayerID = req.PlayerID
+	sess.Style = string(style)
+	sess.Cooldown = cooldown
+	sess.TimeLimit = timeLimit
+	if req.Mode == quiz.ModeEndless {
+		sess.Mode = quiz.ModeEndless
+		// the best streak comes from the player's history, never from the client
+		if req.PlayerID != "" {
+			sess.BestStreak = quiz.BestStreak(h.history.Player(req.PlayerID))
 		}
 	}
 
@@ -202,9 +189,22 @@ type guessRequest struct {
 	Answer    string `json:"answer"`
 }
 type guessResponse struct {
-	Correct    bool `json:"correct"`
-	Solved     bool `json:"solved"`
-	RetryAfter int  `json:"retryAfter"`
+	Correct      bool            `json:"correct"`
+	Solved       bool            `json:"solved"`
+	TimedOut     bool            `json:"timedOut"`         // the time limit ran out; the guess was not counted
+	RetryAfter   int             `json:"retryAfter"`       // seconds, rounded up
+	RetryAfterMs int64           `json:"retryAfterMs"`     // until the next guess is accepted
+	SessionID    string          `json:"sessionId"`        // may change after every update (token sessions)
+	Result       *resultResponse `json:"result,omitempty"` // set once the session is finished
+	// NextSessionID continues an endless streak after a solve; empty once every candidate was used
+	NextSessionID string `json:"nextSessionId,omitempty"`
+}
+
+// retryAfter converts a cooldown into whole seconds (for the Retry-After header) and milliseconds, both rounded up
+// so clients never retry a moment too early; a cooldown that just ran out still asks for 1ms.
+func retryAfter(d time.Duration) (secs int, ms int64) {
+	d = max(d, time.Millisecond)
+	return int((d + time.Second - 1) / time.Second), int64((d + time.Millisecond - 1) / time.Millisecond)
 }
 
 func (h *Handlers) guess(w stdhttp.ResponseWriter, r *stdhttp.Request) {
@@ -214,35 +214,179 @@ func (h *Handlers) guess(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		return
 	}
 
-	sess, ok := h.store.Get(req.SessionID)
-	if !ok {
+	var correct bool
+	var guessErr error
+	sess, err := h.store.Update(req.SessionID, func(s *quiz.Session) error {
+		correct, guessErr = s.SubmitGuess(req.Answer)
+		if guessErr == quiz.ErrTimeUp {
+			return nil // keep the timed-out state
+		}
+		return guessErr
+	})
+	if err == nil {
+		err = guessErr
+	}
+	if err == quiz.ErrSessionNotFound {
 		httpError(w, 404, "session not found")
 		return
 	}
 
-	correct, err := sess.SubmitGuess(req.Answer)
 	if err == quiz.ErrTooSoon {
-		remaining := int((quiz.AllowedGuessInterval - time.Since(sess.LastGuessAt)).Seconds())
-		writeJSON(w, guessResponse{Correct: false, Solved: false, RetryAfter: remaining})
+		secs, ms := retryAfter(sess.RetryAfter())
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+		writeJSON(w, guessResponse{Correct: false, Solved: false, RetryAfter: secs, RetryAfterMs: ms, SessionID: sess.ID})
+		return
+	}
+
+	if err == quiz.ErrTimeUp {
+		h.recordFinish(sess)
+		result := h.result(sess)
+		writeJSON(w, guessResponse{Correct: false, Solved: false, TimedOut: true, SessionID: sess.ID, Result: &result})
 		return
 	}
 
 	if err == quiz.ErrAlreadyFinished {
-		writeJSON(w, guessResponse{Correct: false, Solved: true})
+		// the session may have been solved, given up, missed or timed out; result tells the client which
+		// a solved endless session hands out its successor again, so a lost response does not strand the run
+		result := h.result(sess)
+		writeJSON(w, guessResponse{Correct: false, Solved: sess.Solved, TimedOut: sess.TimedOut, SessionID: sess.ID, Result: &result, NextSessionID: sess.NextID})
 		return
 	}
 
-	writeJSON(w, guessResponse{Correct: correct, Solved: sess.Solved})
+	if err != nil {
+		httpError(w, 500, err.Error())
+		return
+	}
+
+	resp := guessResponse{Correct: correct, Solved: sess.Solved, SessionID: sess.ID}
+	if sess.Finished() {
+		h.recordFinish(sess)
+		result := h.result(sess)
+		resp.Result = &result
+	}
+	// only the request that solved the session chains the streak
+	if correct && sess.Endless() {
+		next, err := h.nextInStreak(sess)
+		if err != nil {
+			httpError(w, 500, err.Error())
+			return
+		}
+		if next != nil {
+			// remember the successor on the solved session so a reload or a retried guess can find it
+			if sess, err = h.store.Update(sess.ID, func(s *quiz.Session) error {
+				s.NextID = next.ID
+				return nil
+			}); err != nil {
+				httpError(w, 500, err.Error())
+				return
+			}
+			resp.SessionID = sess.ID
+			resp.NextSessionID = next.ID
+		}
+	}
+	writeJSON(w, resp)
+}
+
+// nextInStreak starts the next session of the endless streak that prev solved, picking from the same settings
+// without repeating; it returns nil when every candidate has been used
+func (h *Handlers) nextInStreak(prev *quiz.Session) (*quiz.Session, error) {
+	p, err := h.buildPool(settingsOf(prev))
+	if err != nil {
+		return nil, err
+	}
+	if prev.Seeded && p.partial {
+		return nil, errors.New("pokeapi is unavailable, so the replayed run cannot continue now")
+	}
+	picked, ok := h.pickNext(p, prev)
+	if !ok {
+		return nil, nil
+	}
+	next := p.newSession(picked)
+	next.Difficulty = quiz.DifficultyOf(h.difficulty, next.PokemonID)
+	prev.Continue(next)
+	h.store.Set(next)
+	return next, nil
+}
+
+// pickNext draws the pokemon after prev in its streak from p, skipping those already asked
+func (h *Handlers) pickNext(p *pool, prev *quiz.Session) (candidate, bool) {
+	left := p.excluding(prev.StreakUsed())
+	if len(left) == 0 {
+		return candidate{}, false
+	}
+	// round n of a run always draws from the same PRNG state, so a replayed seed gives the same run
+	rng := quiz.SeededRand(prev.Seed, prev.StreakLength())
+	return h.pickCandidate(rng, left, prev.Pick, prev.PlayerID), true
 }
 
 type giveupRequest struct {
 	SessionID string `json:"sessionId"`
 }
 type resultResponse struct {
-	PokemonID int      `json:"pokemonId"`
-	Name      string   `json:"name"`
-	Types     []string `json:"types"`
-	Region    string   `json:"region"`
+	PokemonID int             `json:"pokemonId"`
+	SpeciesID int             `json:"speciesId"`
+	Name      string          `json:"name"` // Japanese when known, else the API slug
+	NameJa    string          `json:"nameJa"`
+	NameEn    string          `json:"nameEn"`
+	Form      string          `json:"form"` // e.g. "mega-x", "alola"; empty for the default form
+	Types     []string        `json:"types"`
+	Region    string          `json:"region"`
+	Genus     string          `json:"genus"`
+	DexEntry  string          `json:"dexEntry"`
+	Streak    *streakResponse `json:"streak,omitempty"` // endless mode only
+	Daily     string          `json:"daily,omitempty"`  // daily challenge date
+	Seed      string          `json:"seed"`             // the seed the pick was drawn with
+	Score     int             `json:"score"`            // 0 unless solved (or unranked); higher for harder pokemon
+	Ranked    bool            `json:"ranked"`           // false with a token store: no score, rating or history
+	// Difficulty is the pokemon's rating (0..1) when the session started
+	Difficulty float64 `json:"difficulty"`
+	// Rating holds the Elo ratings after this session (sessions with a playerId only)
+	Rating *ratingResponse `json:"rating,omitempty"`
+	// Replay holds the start settings reproducing this quiz (POST it to /api/quiz/start, adding a playerId);
+	// nil when they cannot, e.g. for picks that follow play statistics or a daily challenge
+	Replay    *startRequest `json:"replay,omitempty"`
+	SessionID string        `json:"sessionId"`
+}
+
+// replayOf returns the start settings reproducing sess, or nil if its seed does not reproduce it
+func replayOf(sess *quiz.Session) *startRequest {
+	if sess.Mode == quiz.ModeDaily || sess.Partial || !reproducible(sess.Pick, sess.MinDifficulty) {
+		return nil
+	}
+	cooldown, ok := quiz.CooldownName(sess.Cooldown)
+	if !ok {
+		return nil
+	}
+	regions := sess.Regions
+	if regions == nil {
+		regions = []string{}
+	}
+	return &startRequest{
+		Regions:     regions,
+		AllowMega:   sess.AllowMega,
+		AllowPrimal: sess.AllowPrimal,
+		Ambiguity:   sess.Ambiguity,
+		Style:       sess.Style,
+		Cooldown:    cooldown,
+		TimeLimit:   int(sess.TimeLimit / time.Second),
+		Mode:        sess.Mode,
+		Seed:        strconv.FormatUint(sess.Seed, 10),
+		Pick:        sess.Pick,
+	}
+}
+
+type streakResponse struct {
+	Current int  `json:"current"` // pokemon solved in a row, including this session once solved
+	Best    int  `json:"best"`
+	Ended   bool `json:"ended"` // a wrong guess, give-up or timeout ended the streak
+}
+
+// streakOf reports the endless streak of sess (nil for other modes)
+func streakOf(sess *quiz.Session) *streakResponse {
+	if !sess.Endless() {
+		return nil
+	}
+	return &streakResponse{Current: sess.StreakLength(), Best: sess.Best(), Ended: sess.Finished() && !sess.Solved}
 }
 
 func (h *Handlers) giveup(w stdhttp.ResponseWriter, r *stdhttp.Request) {
@@ -252,19 +396,158 @@ func (h *Handlers) giveup(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		return
 	}
 
-	sess, ok := h.store.Get(req.SessionID)
-	if !ok {
+	sess, err := h.store.Update(req.SessionID, func(s *quiz.Session) error {
+		s.GiveUp()
+		return nil
+	})
+	if err == quiz.ErrSessionNotFound {
 		httpError(w, 404, "session not found")
 		return
 	}
-	sess.GiveUp()
+	if err != nil {
+		httpError(w, 500, err.Error())
+		return
+	}
+
+	h.recordFinish(sess)
+	writeJSON(w, h.result(sess))
+}
 
-	name := sess.PokemonName
+// result builds the answer payload of a finished session. Species details are best effort:
+// if PokeAPI is unavailable the session's own fields are still returned.
+func (h *Handlers) result(sess *quiz.Session) resultResponse {
+	res := resultResponse{
+		PokemonID:  sess.PokemonID,
+		SpeciesID:  sess.PokemonID,
+		Name:       sess.PokemonName,
+		NameJa:     sess.DisplayName,
+		NameEn:     englishName(sess.PokemonName),
+		Types:      sess.Types,
+		Region:     sess.RegionKey,
+		Streak:     streakOf(sess),
+		Daily:      sess.Daily,
+		Seed:       strconv.FormatUint(sess.Seed, 10),
+		Difficulty: sess.Difficulty,
+		Ranked:     h.ranked,
+		Replay:     replayOf(sess),
+		SessionID:  sess.ID,
+	}
+	if h.ranked {
+		res.Score = quiz.Score(sess)
+		res.Rating = h.ratingOf(sess)
+	}
 	if sess.DisplayName != "" {
-		name = sess.DisplayName
+		res.Name = sess.DisplayName
 	}
 
-	writeJSON(w, resultResponse{PokemonID: sess.PokemonID, Name: name, Types: sess.Types, Region: sess.RegionKey})
+	p, err := h.poke.GetPokemon(sess.PokemonID)
+	if err != nil {
+		return res
+	}
+	res.SpeciesID = p.SpeciesID()
+	if p.Species.Name != "" && p.Name != p.Species.Name {
+		res.Form = strings.TrimPrefix(p.Name, p.Species.Name+"-")
+	}
+
+	sp, err := h.poke.GetSpecies(res.SpeciesID)
+	if err != nil {
+		return res
+	}
+	res.Genus = sp.Genus()
+	res.DexEntry = sp.FlavorText()
+	return res
+}
+
+type sessionSettings struct {
+	Regions        []string `json:"regions"`
+	AllowMega      bool     `json:"allowMega"`
+	AllowPrimal    bool     `json:"allowPrimal"`
+	Style          string   `json:"style"`
+	CooldownMs     int64    `json:"cooldownMs"`     // base wait between guesses
+	CooldownStepMs int64    `json:"cooldownStepMs"` // added per consecutive wrong guess
+	CooldownMaxMs  int64    `json:"cooldownMaxMs"`  // cap for escalation (0 = none)
+	TimeLimitMs    int64    `json:"timeLimitMs"`    // time attack limit (0 = none)
+	Mode           string   `json:"mode"`
+}
+
+// sessionResponse is the resumable state of a session; Result is only set once finished
+type sessionResponse struct {
+	SessionID           string          `json:"sessionId"`
+	StartedAt           time.Time       `json:"startedAt"`
+	FinishedAt          *time.Time      `json:"finishedAt,omitempty"`
+	Solved              bool            `json:"solved"`
+	GaveUp              bool            `json:"gaveUp"`
+	TimedOut            bool            `json:"timedOut"`
+	Missed              bool            `json:"missed"` // a wrong guess ended the endless streak
+	Guesses             int             `json:"guesses"`
+	HintsUsed           int             `json:"hintsUsed"`
+	HintsRevealed       []string        `json:"hintsRevealed"`
+	CooldownRemainingMs int64           `json:"cooldownRemainingMs"`
+	TimeRemainingMs     *int64          `json:"timeRemainingMs,omitempty"` // only with a time limit
+	Settings            sessionSettings `json:"settings"`
+	Events              []quiz.Event    `json:"events"`
+	Streak              *streakResponse `json:"streak,omitempty"`
+	Result              *resultResponse `json:"result,omitempty"`
+	NextSessionID       string          `json:"nextSessionId,omitempty"` // endless: the session after this solve
+}
+
+// sessionByID returns non-spoiling state for resuming a quiz (e.g. after a page reload),
+// plus the answer once the quiz is finished
+func (h *Handlers) sessionByID(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	sid := chi.URLParam(r, "sessionId")
+	sess, ok := h.store.Get(sid)
+	if !ok {
+		httpError(w, 404, "session not found")
+		return
+	}
+	sess.CheckDeadline() // on the snapshot only: report an expired time limit before the store settles it
+
+	events := sess.Events
+	if events == nil {
+		events = []quiz.Event{}
+	}
+	regions := sess.Regions
+	if regions == nil {
+		regions = []string{}
+	}
+	resp := sessionResponse{
+		SessionID:     sess.ID,
+		StartedAt:     sess.StartedAt,
+		Solved:        sess.Solved,
+		GaveUp:        sess.GaveUp,
+		TimedOut:      sess.TimedOut,
+		Missed:        sess.Missed,
+		Guesses:       sess.Guesses,
+		HintsUsed:     sess.HintsUsed,
+		HintsRevealed: sess.HintsRevealed(),
+		Settings: sessionSettings{
+			Regions:        regions,
+			AllowMega:      sess.AllowMega,
+			AllowPrimal:    sess.AllowPrimal,
+			Style:          sess.Style,
+			CooldownMs:     sess.Cooldown.Base.Milliseconds(),
+			CooldownStepMs: sess.Cooldown.Step.Milliseconds(),
+			CooldownMaxMs:  sess.Cooldown.Max.Milliseconds(),
+			TimeLimitMs:    sess.TimeLimit.Milliseconds(),
+			Mode:           sess.Mode,
+		},
+		Events:        events,
+		Streak:        streakOf(sess),
+		NextSessionID: sess.NextID,
+	}
+	if sess.TimeLimit > 0 {
+		remaining := sess.TimeRemaining().Milliseconds()
+		resp.TimeRemainingMs = &remaining
+	}
+	if sess.Finished() { // answer only after finish; a time-up is booked when the store settles it
+		finished := sess.FinishedAt
+		resp.FinishedAt = &finished
+		result := h.result(sess)
+		resp.Result = &result
+	} else {
+		resp.CooldownRemainingMs = sess.RetryAfter().Milliseconds()
+	}
+	writeJSON(w, resp)
 }
 
 func (h *Handlers) silhouetteBySession(w stdhttp.ResponseWriter, r *stdhttp.Request) {
@@ -275,20 +558,21 @@ func (h *Handlers) silhouetteBySession(w stdhttp.ResponseWriter, r *stdhttp.Requ
 		return
 	}
 
-	img, err := h.poke.GetOfficialArtwork(sess.PokemonID)
+	opts, err := renderOptions(r)
 	if err != nil {
-		httpError(w, 404, err.Error())
+		httpError(w, 400, err.Error())
 		return
 	}
+	opts.Style = poke.Style(sess.Style)
+	opts.Silhouette = true
 
-	data, err := poke.ToSilhouette(img)
+	data, err := h.poke.Render(sess.PokemonID, opts)
 	if err != nil {
-		httpError(w, 500, err.Error())
+		httpError(w, 404, err.Error())
 		return
 	}
 
-	w.Header().Set("Content-Type", "image/png")
-	w.Write(data)
+	writeImage(w, r, opts.Format.ContentType(), data)
 }
 
 // artworkBySession returns the original official artwork PNG (color) after quiz finished (solved or gave up)
@@ -300,32 +584,132 @@ func (h *Handlers) artworkBySession(w stdhttp.ResponseWriter, r *stdhttp.Request
 		return
 	}
 
-	if !sess.Solved && !sess.GaveUp { // forbid early reveal
+	if !sess.Finished() { // forbid early reveal
 		httpError(w, 403, "not revealed yet")
 		return
 	}
 
-	img, err := h.poke.GetOfficialArtwork(sess.PokemonID)
+	opts, err := renderOptions(r)
+	if err != nil {
+		httpError(w, 400, err.Error())
+		return
+	}
+	opts.Style = poke.Style(sess.Style)
+	opts.Mask = poke.SilhouetteOptions{} // colour artwork has no mask; keep one cache entry
+
+	data, err := h.poke.Render(sess.PokemonID, opts)
 	if err != nil {
 		httpError(w, 404, err.Error())
 		return
 	}
-	// re-encode as PNG (original could already be PNG; we just encode our image.Image)
-	// Simple approach: use standard library png encoder.
-	w.Header().Set("Content-Type", "image/png")
-	// encode
-	// Avoid importing image/png at top? bring here (already imported via underscore in client elsewhere) need explicit import.
-	// We'll add png import at top of file.
-	if err := png.Encode(w, img); err != nil {
+
+	writeImage(w, r, opts.Format.ContentType(), data)
+}
+
+// revealDefaultSize keeps reveal GIFs shareable when no size is requested
+const revealDefaultSize = 240
+
+// revealBySession returns an animated GIF morphing the silhouette into the artwork after quiz finished (solved or gave up)
+func (h *Handlers) revealBySession(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	sid := chi.URLParam(r, "sessionId")
+	sess, ok := h.store.Get(sid)
+	if !ok {
+		httpError(w, 404, "session not found")
+		return
+	}
+
+	if !sess.Finished() { // forbid early reveal
+		httpError(w, 403, "not revealed yet")
+		return
+	}
+
+	opts, err := renderOptions(r)
+	if err != nil {
+		httpError(w, 400, err.Error())
+		return
+	}
+	opts.Style = poke.Style(sess.Style)
+	opts.Reveal = true
+	opts.Format = poke.FormatGIF
+	opts.Background = color.NRGBA{}
+	if opts.Size == 0 {
+		opts.Size = revealDefaultSize
+	}
+
+	data, err := h.poke.Render(sess.PokemonID, opts)
+	if err != nil {
+		httpError(w, 404, err.Error())
+		return
+	}
+
+	writeImage(w, r, "image/gif", data)
+}
+
+// resultCardBySession returns a shareable PNG card (artwork, names, types, time, guesses, hints) after quiz finished
+func (h *Handlers) resultCardBySession(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	sid := chi.URLParam(r, "sessionId")
+	sess, ok := h.store.Get(sid)
+	if !ok {
+		httpError(w, 404, "session not found")
+		return
+	}
+
+	if !sess.Finished() { // forbid early reveal
+		httpError(w, 403, "not revealed yet")
+		return
+	}
+
+	img, err := h.poke.GetArtwork(sess.PokemonID, poke.Style(sess.Style))
+	if err != nil {
+		httpError(w, 404, err.Error())
+		return
+	}
+
+	types := make([]poke.Label, 0, len(sess.Types))
+	for _, t := range sess.Types {
+		types = append(types, poke.Label{JP: typeJP[t], EN: strings.ToUpper(t[:1]) + t[1:]})
+	}
+	card := poke.ResultCard{
+		Artwork:  img,
+		Name:     poke.Label{JP: sess.DisplayName, EN: englishName(sess.PokemonName)},
+		Types:    types,
+		Region:   poke.Label{JP: regionJP(sess.RegionKey), EN: englishName(sess.RegionKey)},
+		Solved:   sess.Solved,
+		TimedOut: sess.TimedOut,
+		Missed:   sess.Missed,
+		Elapsed:  sess.Elapsed(),
+		Guesses:  sess.Guesses,
+		Hints:    sess.HintsUsed,
+	}
+	data, err := poke.RenderCard(card)
+	if err != nil {
 		httpError(w, 500, err.Error())
 		return
 	}
+
+	writeImage(w, r, "image/png", data)
+}
+
+// englishName turns an API slug like "charizard-mega-x" into "Charizard Mega X"
+func englishName(slug string) string {
+	parts := strings.Split(slug, "-")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, " ")
 }
 
+type hintRequest struct {
+	SessionID string `json:"sessionId"`
+	Kind      string `json:"kind"` // "type", "region", "first" or ""
+}
 type hintResponse struct {
 	Types       []string `json:"types"`
 	Region      string   `json:"region"`
 	FirstLetter string   `json:"firstLetter"`
+	SessionID   string   `json:"sessionId"`
 }
 
 var typeJP = map[string]string{
@@ -341,13 +725,35 @@ func regionJP(key string) string {
 	return key
 }
 
-func (h *Handlers) hintBySession(w stdhttp.ResponseWriter, r *stdhttp.Request) {
-	sid := chi.URLParam(r, "sessionId")
-	sess, ok := h.store.Get(sid)
-	if !ok {
+// hintKinds are the values accepted as kind on the hint endpoint (recorded in the session log)
+var hintKinds = map[string]bool{"": true, "type": true, "region": true, "first": true}
+
+// hint reveals a hint; it is a POST because it counts the hint and logs it (and re-issues token session ids)
+func (h *Handlers) hint(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	var req hintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpError(w, 400, err.Error())
+		return
+	}
+	if !hintKinds[req.Kind] {
+		httpError(w, 400, "kind must be type, region or first")
+		return
+	}
+	sess, err := h.store.Update(req.SessionID, func(s *quiz.Session) error {
+		return s.UseHint(req.Kind)
+	})
+	if err == quiz.ErrSessionNotFound {
 		httpError(w, 404, "session not found")
 		return
 	}
+	if err == quiz.ErrAlreadyFinished {
+		httpError(w, 409, "quiz already finished")
+		return
+	}
+	if err != nil {
+		httpError(w, 500, err.Error())
+		return
+	}
 
 	// first letter: prefer display name (Japanese) else english
 	name := sess.DisplayName
@@ -369,7 +775,7 @@ func (h *Handlers) hintBySession(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 			tJP = append(tJP, t)
 		}
 	}
-	writeJSON(w, hintResponse{Types: tJP, Region: regionJP(sess.RegionKey), FirstLetter: first})
+	writeJSON(w, hintResponse{Types: tJP, Region: regionJP(sess.RegionKey), FirstLetter: first, SessionID: sess.ID})
 }
 
 // search returns list of candidate names (Japanese if available else English)
@@ -404,6 +810,93 @@ func (h *Handlers) search(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 	writeJSON(w, out)
 }
 
+// maxMinSpeck bounds ?speck so a request can't blank the whole silhouette
+const maxMinSpeck = 10000
+
+// renderOptions reads ?format=png|jpeg|gif|svg, ?size=N (longest edge px), ?bg=RRGGBB (JPEG background)
+// and the silhouette mask options ?threshold=0-254, ?aa=1 (anti-aliased edges), ?speck=N (min region px)
+func renderOptions(r *stdhttp.Request) (poke.RenderOptions, error) {
+	q := r.URL.Query()
+	opts := poke.RenderOptions{
+		Background: color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Mask:       poke.DefaultMask,
+	}
+
+	f, err := poke.ParseFormat(q.Get("format"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Format = f
+
+	if v := q.Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || !poke.ValidSize(n) {
+			return opts, poke.ErrInvalidSize
+		}
+		opts.Size = n
+	}
+
+	if v := strings.TrimPrefix(q.Get("bg"), "#"); v != "" {
+		rgb, err := hex.DecodeString(v)
+		if err != nil || len(rgb) != 3 {
+			return opts, fmt.Errorf("bg must be RRGGBB")
+		}
+		opts.Background = color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 255}
+	}
+	if opts.Format != poke.FormatJPEG {
+		opts.Background = color.NRGBA{} // unused; don't let ?bg= split the render cache
+	}
+
+	if v := q.Get("threshold"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 254 {
+			return opts, fmt.Errorf("threshold must be between 0 and 254")
+		}
+		opts.Mask.Threshold = uint8(n)
+	}
+	if v := q.Get("speck"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxMinSpeck {
+			return opts, fmt.Errorf("speck must be between 0 and %d", maxMinSpeck)
+		}
+		opts.Mask.MinSpeck = n
+	}
+	opts.Mask.AntiAlias = q.Get("aa") == "1" || q.Get("aa") == "true"
+	return opts, nil
+}
+
+// writeImage serves per-session image bytes with a strong ETag and answers If-None-Match with 304
+func writeImage(w stdhttp.ResponseWriter, r *stdhttp.Request, contentType string, data []byte) {
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	// session images never change once issued, but they belong to one player's quiz
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(stdhttp.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+// etagMatch reports whether an If-None-Match header value matches etag (weak comparison per RFC 9110)
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func writeJSON(w stdhttp.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(v)
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/poke"
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+// serve sends one request to h's routes and returns the recorded response
+func serve(h *Handlers, method, target, body string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	h.Register(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestStartRefusesAmbiguityWithoutGroups(t *testing.T) {
+	h := testHandlers()
+	if h.poke.HasAmbiguityGroups() {
+		t.Skip("ambiguity groups are embedded")
+	}
+	for _, mode := range []string{"exclude", "accept"} {
+		w := serve(h, "POST", "/api/quiz/start", `{"regions":["kanto"],"ambiguity":"`+mode+`"}`)
+		if w.Code != 503 {
+			t.Errorf("ambiguity=%s: status %d, want 503", mode, w.Code)
+		}
+	}
+}
+
+func TestTokenSessionsAreUnranked(t *testing.T) {
+	ts, err := quiz.NewTokenStore(bytes.Repeat([]byte{7}, 32), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := quiz.NewMemoryHistory(0)
+	h := NewHandlers(poke.NewClient(time.Minute), ts, quiz.NewMemoryDailyBook(), history, quiz.NewMemoryDifficulty(), quiz.NewMemoryRatings())
+
+	if w := serve(h, "POST", "/api/quiz/daily/start", `{"playerId":"p1"}`); w.Code != 501 {
+		t.Errorf("daily start: status %d, want 501", w.Code)
+	}
+	for _, pick := range []string{pickMissed, pickAdaptive} {
+		if w := serve(h, "POST", "/api/quiz/start", `{"pick":"`+pick+`","playerId":"p1"}`); w.Code != 400 {
+			t.Errorf("pick=%s: status %d, want 400", pick, w.Code)
+		}
+	}
+
+	sess := quiz.NewSession(25, "pikachu", "kanto", []string{"electric"}, false, false)
+	sess.PlayerID = "p1"
+	sess.Cooldown = quiz.CooldownPractice
+	ts.Set(sess)
+	w := serve(h, "POST", "/api/quiz/guess", `{"sessionId":"`+sess.ID+`","answer":"pikachu"}`)
+	var resp guessResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Solved || resp.Result == nil {
+		t.Fatalf("guess: %+v", resp)
+	}
+	if resp.Result.Ranked || resp.Result.Score != 0 || resp.Result.Rating != nil {
+		t.Errorf("token result is scored: ranked %v, score %d, rating %v", resp.Result.Ranked, resp.Result.Score, resp.Result.Rating)
+	}
+	if recs := history.Player("p1"); len(recs) != 0 {
+		t.Errorf("token session was recorded: %v", recs)
+	}
+}
+
+func TestSeedNeedsReproducibleSettings(t *testing.T) {
+	h := testHandlers()
+	for _, body := range []string{
+		`{"seed":"42","pick":"difficulty"}`,
+		`{"seed":"42","pick":"missed","playerId":"p1"}`,
+		`{"seed":"42","pick":"adaptive","playerId":"p1"}`,
+		`{"seed":"42","minDifficulty":0.6}`,
+	} {
+		if w := serve(h, "POST", "/api/quiz/start", body); w.Code != 400 {
+			t.Errorf("%s: status %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestReplayOf(t *testing.T) {
+	sess := quiz.NewSession(25, "pikachu", "kanto", []string{"electric"}, true, false)
+	sess.Regions = []string{"kanto", "johto"}
+	sess.Ambiguity = ambiguityAccept
+	sess.Style = "pixel"
+	sess.Cooldown = quiz.CooldownEscalating
+	sess.TimeLimit = 90 * time.Second
+	sess.Mode = quiz.ModeEndless
+	sess.Seed = 1<<63 + 5
+	sess.Pick = pickSpecies
+
+	got := replayOf(sess)
+	want := &startRequest{Regions: []string{"kanto", "johto"}, AllowMega: true, Ambiguity: ambiguityAccept, Style: "pixel", Cooldown: "escalating", TimeLimit: 90, Mode: quiz.ModeEndless, Seed: "9223372036854775813", Pick: pickSpecies}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replay = %+v, want %+v", got, want)
+	}
+
+	for name, change := range map[string]func(*quiz.Session){
+		"pick=difficulty": func(s *quiz.Session) { s.Pick = pickDifficulty },
+		"pick=missed":     func(s *quiz.Session) { s.Pick = pickMissed },
+		"minDifficulty":   func(s *quiz.Session) { s.MinDifficulty = 0.6 },
+		"daily":           func(s *quiz.Session) { s.Mode = quiz.ModeDaily },
+		"partial pool":    func(s *quiz.Session) { s.Partial = true },
+	} {
+		cp := sess.Clone()
+		change(cp)
+		if r := replayOf(cp); r != nil {
+			t.Errorf("%s: replay = %+v, want none", name, r)
+		}
+	}
+}
+
+func TestTimeUpBookedByJanitorNotByGet(t *testing.T) {
+	store := quiz.NewMemoryStore(time.Hour, 0)
+	history := quiz.NewMemoryHistory(0)
+	h := NewHandlers(poke.NewClient(time.Minute), store, quiz.NewMemoryDailyBook(), history, quiz.NewMemoryDifficulty(), quiz.NewMemoryRatings())
+
+	sess := quiz.NewSession(25, "pikachu", "kanto", []string{"electric"}, false, false)
+	sess.PlayerID = "p1"
+	sess.TimeLimit = time.Minute
+	sess.StartedAt = time.Now().Add(-2 * time.Minute)
+	store.Set(sess)
+
+	w := serve(h, "GET", "/api/quiz/session/"+sess.ID, "")
+	var resp sessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.TimedOut || resp.Result == nil {
+		t.Fatalf("GET after the deadline: %s", w.Body)
+	}
+	if recs := history.Player("p1"); len(recs) != 0 {
+		t.Fatalf("GET booked %d records", len(recs))
+	}
+	if stored, _ := store.Get(sess.ID); stored.TimedOut {
+		t.Fatal("GET stored the time-up")
+	}
+
+	store.StartJanitor(time.Millisecond)
+	defer store.Close()
+	for deadline := time.Now().Add(5 * time.Second); len(history.Player("p1")) == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("janitor never booked the time-up")
+		}
+	}
+	if recs := history.Player("p1"); len(recs) != 1 || !recs[0].TimedOut {
+		t.Fatalf("booked %+v, want one time-up", recs)
+	}
+}
+
+func TestRetryAfterRoundsUp(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		secs int
+		ms   int64
+	}{
+		{0, 1, 1},
+		{-time.Second, 1, 1},
+		{time.Nanosecond, 1, 1},
+		{time.Millisecond, 1, 1},
+		{time.Millisecond + time.Nanosecond, 1, 2},
+		{999 * time.Millisecond, 1, 999},
+		{time.Second, 1, 1000},
+		{time.Second + time.Microsecond, 2, 1001},
+		{4500 * time.Millisecond, 5, 4500},
+		{30 * time.Second, 30, 30000},
+	} {
+		if secs, ms := retryAfter(tc.d); secs != tc.secs || ms != tc.ms {
+			t.Errorf("retryAfter(%v) = %ds, %dms; want %ds, %dms", tc.d, secs, ms, tc.secs, tc.ms)
+		}
+	}
+}
+
+func TestGuessTooSoonSetsRetryAfter(t *testing.T) {
+	h := testHandlers()
+	sess := quiz.NewSession(25, "pikachu", "kanto", []string{"electric"}, false, false)
+	sess.Cooldown = quiz.CooldownEscalating
+	h.store.Set(sess)
+
+	body := `{"sessionId":"` + sess.ID + `","answer":"raichu"}`
+	if w := serve(h, "POST", "/api/quiz/guess", body); w.Code != 200 {
+		t.Fatalf("first guess: status %d", w.Code)
+	}
+	w := serve(h, "POST", "/api/quiz/guess", body)
+	var resp guessResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.RetryAfter != 5 || resp.RetryAfterMs <= 4000 || resp.RetryAfterMs > 5000 {
+		t.Fatalf("retryAfter = %ds / %dms, want 5s / up to 5000ms", resp.RetryAfter, resp.RetryAfterMs)
+	}
+	if got := w.Header().Get("Retry-After"); got != "5" {
+		t.Fatalf("Retry-After header = %q, want 5", got)
+	}
+}
+
+func TestWriteImageConditional(t *testing.T) {
+	data := []byte("png bytes")
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/image", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		writeImage(w, r, "image/png", data)
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != 200 || first.Body.String() != string(data) || first.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("unconditional GET: status %d, type %q, body %q", first.Code, first.Header().Get("Content-Type"), first.Body)
+	}
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || len(etag) < 3 {
+		t.Fatalf("ETag %q is not a quoted strong tag", etag)
+	}
+
+	for _, tc := range []struct {
+		header string
+		want   int
+	}{
+		{etag, 304},
+		{"W/" + etag, 304},
+		{"*", 304},
+		{`"other", ` + etag, 304},
+		{`"other",W/` + etag + `, "more"`, 304},
+		{`"other"`, 200},
+		{`"other", W/"another"`, 200},
+		{etag[:len(etag)-2] + `"`, 200}, // a prefix of the tag
+		{strings.Trim(etag, `"`), 200},  // unquoted
+		{" , ", 200},
+	} {
+		w := get(tc.header)
+		if w.Code != tc.want {
+			t.Errorf("If-None-Match %q: status %d, want %d", tc.header, w.Code, tc.want)
+			continue
+		}
+		if w.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %q: ETag %q, want %q", tc.header, w.Header().Get("ETag"), etag)
+		}
+		if tc.want == 304 && w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %q: 304 with a %d byte body", tc.header, w.Body.Len())
+		}
+	}
+
+	// other content gets another tag
+	w := httptest.NewRecorder()
+	writeImage(w, httptest.NewRequest("GET", "/image", nil), "image/png", []byte("other bytes"))
+	if w.Header().Get("ETag") == etag {
+		t.Error("different images share an ETag")
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"math/rand/v2"
+
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+// Selection strategies accepted as startRequest.pick
+const (
+	pickUniform    = "uniform"    // every candidate (form) equally likely
+	pickSpecies    = "species"    // every species equally likely, however many forms it has
+	pickRegion     = "region"     // every region equally likely, then uniform within it
+	pickDifficulty = "difficulty" // favor pokemon with a high difficulty rating
+	pickMissed     = "missed"     // favor pokemon this player failed before
+	pickAdaptive   = "adaptive"   // favor pokemon whose Elo rating is close to this player's
+)
+
+var errUnknownPick = errors.New("pick must be uniform, species, region, difficulty, missed or adaptive")
+var errPickNeedsPlayer = errors.New("pick=missed and pick=adaptive require playerId")
+var errSeedNotReproducible = errors.New("seed cannot be combined with pick=difficulty, missed or adaptive, or with minDifficulty: they depend on play statistics that change over time")
+var errPickNeedsRanked = errors.New("pick=missed and pick=adaptive need play history, which unranked (token) sessions do not keep")
+
+// validPick checks a strategy name ("" means uniform)
+func validPick(pick, player string) error {
+	switch pick {
+	case "", pickUniform, pickSpecies, pickRegion, pickDifficulty:
+		return nil
+	case pickMissed, pickAdaptive:
+		if player == "" {
+			return errPickNeedsPlayer
+		}
+		return nil
+	}
+	return errUnknownPick
+}
+
+// reproducible reports whether a seed gives the same pick under pick and minDifficulty whoever starts the quiz and
+// whenever: weights and filters that follow play statistics (difficulty, history, ratings) drift
+func reproducible(pick string, minDifficulty float64) bool {
+	switch pick {
+	case pickDifficulty, pickMissed, pickAdaptive:
+		return false
+	}
+	return minDifficulty == 0
+}
+
+// pickCandidate draws one of cs with rng according to the strategy
+func (h *Handlers) pickCandidate(rng *rand.Rand, cs []candidate, pick, player string) candidate {
+	return cs[quiz.WeightedIndex(rng, h.weights(cs, pick, player))]
+}
+
+// weights returns the relative selection weight of each candidate
+func (h *Handlers) weights(cs []candidate, pick, player string) []float64 {
+	ws := make([]float64, len(cs))
+	switch pick {
+	case pickSpecies:
+		forms := map[int]int{}
+		for _, c := range cs {
+			forms[c.species]++
+		}
+		for i, c := range cs {
+			ws[i] = 1 / float64(forms[c.species])
+		}
+	case pickRegion:
+		inRegion := map[string]int{}
+		for _, c := range cs {
+			inRegion[regionOf(c.species)]++
+		}
+		for i, c := range cs {
+			ws[i] = 1 / float64(inRegion[regionOf(c.species)])
+		}
+	case pickDifficulty:
+		for i, c := range cs {
+			ws[i] = quiz.DifficultyOf(h.difficulty, c.id)
+		}
+	case pickMissed:
+		missed := map[int]int{}
+		for _, r := range h.history.Player(player) {
+			if !r.Solved {
+				missed[r.PokemonID]++
+			}
+		}
+		for i, c := range cs {
+			ws[i] = 1 + missedBoost*float64(missed[c.id])
+		}
+	case pickAdaptive:
+		own := h.ratings.PlayerRating(player).Rating
+		rated := h.ratings.PokemonRatings()
+		for i, c := range cs {
+			theirs := float64(quiz.DefaultRating)
+			if r, ok := rated[c.id]; ok {
+				theirs = r.Rating
+			}
+			ws[i] = quiz.Closeness(own, theirs)
+		}
+	default:
+		for i := range ws {
+			ws[i] = 1
+		}
+	}
+	return ws
+}
+
+// missedBoost is the extra weight per earlier failure with pick=missed
+const missedBoost = 3
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/poke"
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+// testCandidates is a fixed pool spanning regions and forms, so every strategy has something to weigh
+var testCandidates = []candidate{
+	{id: 1, species: 1, name: "bulbasaur"},
+	{id: 4, species: 4, name: "charmander"},
+	{id: 6, species: 6, name: "charizard"},
+	{id: 10034, species: 6, name: "charizard-mega-x"},
+	{id: 10035, species: 6, name: "charizard-mega-y"},
+	{id: 25, species: 25, name: "pikachu"},
+	{id: 152, species: 152, name: "chikorita"},
+	{id: 252, species: 252, name: "treecko"},
+	{id: 387, species: 387, name: "turtwig"},
+	{id: 495, species: 495, name: "snivy"},
+	{id: 650, species: 650, name: "chespin"},
+	{id: 722, species: 722, name: "rowlet"},
+}
+
+var testPicks = []string{"", pickUniform, pickSpecies, pickRegion, pickDifficulty, pickMissed, pickAdaptive}
+
+// testHandlers returns handlers over memory stores with some history, so missed, difficulty and adaptive
+// weights are not all equal
+func testHandlers() *Handlers {
+	h := NewHandlers(poke.NewClient(time.Minute), quiz.NewMemoryStore(0, 0), quiz.NewMemoryDailyBook(), quiz.NewMemoryHistory(0), quiz.NewMemoryDifficulty(), quiz.NewMemoryRatings())
+	for i, id := range []int{6, 25, 6, 722, 152} {
+		r := quiz.Record{Key: string(rune('a' + i)), PlayerID: "p1", PokemonID: id, Solved: id == 25, GaveUp: id != 25}
+		h.history.Add(r)
+		h.difficulty.Observe(r)
+		h.ratings.RateSession(r)
+	}
+	return h
+}
+
+// run plays an endless run of seed to the end, solving every pokemon, and returns the ids in order
+func run(h *Handlers, p *pool, seed uint64, pick string) []int {
+	sess := p.newSession(h.pickCandidate(quiz.SeededRand(seed, 0), p.candidates, pick, "p1"))
+	sess.Mode, sess.Seed, sess.Pick, sess.PlayerID = quiz.ModeEndless, seed, pick, "p1"
+	ids := []int{sess.PokemonID}
+	for {
+		sess.Solved = true
+		c, ok := h.pickNext(p, sess)
+		if !ok {
+			return ids
+		}
+		next := p.newSession(c)
+		sess.Continue(next)
+		sess = next
+		ids = append(ids, sess.PokemonID)
+	}
+}
+
+func TestPickIsDeterministic(t *testing.T) {
+	h := testHandlers()
+	for _, pick := range testPicks {
+		seen := map[int]bool{}
+		for seed := uint64(0); seed < 50; seed++ {
+			a := h.pickCandidate(quiz.SeededRand(seed, 0), testCandidates, pick, "p1")
+			b := h.pickCandidate(quiz.SeededRand(seed, 0), testCandidates, pick, "p1")
+			if a.id != b.id {
+				t.Fatalf("pick=%q seed=%d: got %d then %d", pick, seed, a.id, b.id)
+			}
+			seen[a.id] = true
+		}
+		// the seed must actually drive the pick
+		if len(seen) < 3 {
+			t.Errorf("pick=%q: 50 seeds picked only %v", pick, seen)
+		}
+	}
+}
+
+func TestEndlessRunIsDeterministic(t *testing.T) {
+	h := testHandlers()
+	p := &pool{candidates: testCandidates}
+	for _, pick := range testPicks {
+		first := run(h, p, 42, pick)
+		if again := run(h, p, 42, pick); !slices.Equal(first, again) {
+			t.Fatalf("pick=%q: run of seed 42 was %v, then %v", pick, first, again)
+		}
+		if len(first) != len(testCandidates) {
+			t.Errorf("pick=%q: run asked %d pokemon, want every candidate once (%d)", pick, len(first), len(testCandidates))
+		}
+		sorted := slices.Clone(first)
+		slices.Sort(sorted)
+		if len(slices.Compact(sorted)) != len(first) {
+			t.Errorf("pick=%q: run repeated a pokemon: %v", pick, first)
+		}
+	}
+	if a, b := run(h, p, 42, ""), run(h, p, 43, ""); slices.Equal(a, b) {
+		t.Errorf("seeds 42 and 43 gave the same run %v", a)
+	}
+}
+
+// TestEndlessRunGolden pins the run of one seed, so a change to SeededRand, WeightedIndex or the weights
+// (which would change every replayed seed) is noticed
+func TestEndlessRunGolden(t *testing.T) {
+	got := run(testHandlers(), &pool{candidates: testCandidates}, 42, "")
+	want := []int{10034, 650, 25, 4, 1, 722, 6, 495, 10035, 152, 252, 387}
+	if !slices.Equal(got, want) {
+		t.Errorf("run of seed 42 = %#v, want %#v", got, want)
+	}
+}
+
+func TestAdaptiveWeightsFavourNearbyRatings(t *testing.T) {
+	h := NewHandlers(poke.NewClient(time.Minute), quiz.NewMemoryStore(0, 0), quiz.NewMemoryDailyBook(), quiz.NewMemoryHistory(0), quiz.NewMemoryDifficulty(), quiz.NewMemoryRatings())
+	// p1 keeps solving 10 instantly while p2 keeps failing 30, so p1 and 30 climb by the same amount and 10 sinks
+	for i := 0; i < 10; i++ {
+		h.ratings.RateSession(quiz.Record{PlayerID: "p1", PokemonID: 10, Solved: true})
+		h.ratings.RateSession(quiz.Record{PlayerID: "p2", PokemonID: 30, GaveUp: true})
+	}
+	if p, q := h.ratings.PlayerRating("p1").Rating, h.ratings.PokemonRating(30).Rating; p != q || p <= quiz.DefaultRating {
+		t.Fatalf("p1 rated %v, pokemon 30 %v; want equal and above the default", p, q)
+	}
+
+	cs := []candidate{{id: 10}, {id: 20}, {id: 30}} // 20 was never played
+	ws := h.weights(cs, pickAdaptive, "p1")
+	if ws[2] != 1 || !(ws[2] > ws[1] && ws[1] > ws[0] && ws[0] > 0) {
+		t.Fatalf("weights 10/20/30 = %v, want the even match 30 first, then the unrated 20, then the weak 10", ws)
+	}
+	// a new player is an even match for unrated pokemon
+	if ws := h.weights(cs, pickAdaptive, "new"); ws[1] != 1 || ws[1] <= ws[0] || ws[1] <= ws[2] {
+		t.Fatalf("weights for a new player = %v, want the unrated 20 first", ws)
+	}
+}
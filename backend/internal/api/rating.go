@@ -0,0 +1,46 @@
+package api
+
+import (
+	"math"
+	stdhttp "net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/levyxx/pokemon-silhouette-quiz/backend/internal/quiz"
+)
+
+type ratingResponse struct {
+	Player  int `json:"player"`  // the player's rating
+	Pokemon int `json:"pokemon"` // the pokemon's rating
+	Games   int `json:"games"`   // rated sessions of the player
+}
+
+// ratingOf reports the current ratings of sess's player and pokemon (nil for anonymous sessions);
+// for a finished session that recordFinish already booked they include its outcome
+func (h *Handlers) ratingOf(sess *quiz.Session) *ratingResponse {
+	if sess.PlayerID == "" {
+		return nil
+	}
+	player := h.ratings.PlayerRating(sess.PlayerID)
+	return &ratingResponse{
+		Player:  int(math.Round(player.Rating)),
+		Pokemon: int(math.Round(h.ratings.PokemonRating(sess.PokemonID).Rating)),
+		Games:   player.Games,
+	}
+}
+
+type playerRatingResponse struct {
+	PlayerID string `json:"playerId"`
+	Rating   int    `json:"rating"`
+	Games    int    `json:"games"`
+}
+
+// playerRating returns a player's Elo rating; players without rated sessions report DefaultRating
+func (h *Handlers) playerRating(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	player := chi.URLParam(r, "playerId")
+	if player == "" || len(player) > maxPlayerID {
+		httpError(w, 400, "invalid player id")
+		return
+	}
+	rt := h.ratings.PlayerRating(player)
+	writeJSON(w, playerRatingResponse{PlayerID: player, Rating: int(math.Round(rt.Rating)), Games: rt.Games})
+}
@@ -0,0 +1,269 @@
+package poke
+
+import (
+	_ "embed"
+	"encoding/json"
+	"image"
+	"math/bits"
+	"os"
+	"sort"
+	"time"
+)
+
+// signatureSide is the edge length of the normalized mask grid
+const signatureSide = 32
+
+// DefaultAmbiguityIoU is the mask overlap above which two silhouettes are considered indistinguishable
+const DefaultAmbiguityIoU = 0.9
+
+// MaskSignature is a coarse alpha mask cropped to the silhouette bounds, padded to a centred square and scaled to
+// 32x32, so silhouettes can be compared regardless of where and how large the artwork draws them. Keeping the aspect
+// ratio matters: stretched to fill the grid, a tall and a wide bar would both become a full square.
+type MaskSignature [signatureSide * signatureSide / 64]uint64
+
+// Signature computes the mask signature of src with the given silhouette options
+func Signature(src image.Image, o SilhouetteOptions) MaskSignature {
+	mask := Silhouette(src, o)
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+
+	// bounding box of solid pixels
+	minX, minY, maxX, maxY := w, h, -1, -1
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if mask.Pix[y*mask.Stride+x*4+3] != 0 {
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+
+	var sig MaskSignature
+	if maxX < 0 {
+		return sig
+	}
+	bw, bh := maxX-minX+1, maxY-minY+1
+	side := max(bw, bh)
+	// the square may reach past the image; samples there are empty
+	x0, y0 := minX-(side-bw)/2, minY-(side-bh)/2
+	for gy := 0; gy < signatureSide; gy++ {
+		for gx := 0; gx < signatureSide; gx++ {
+			// sample the centre of each grid cell
+			x := x0 + (2*gx+1)*side/(2*signatureSide)
+			y := y0 + (2*gy+1)*side/(2*signatureSide)
+			if x < 0 || y < 0 || x >= w || y >= h {
+				continue
+			}
+			if mask.Pix[y*mask.Stride+x*4+3] != 0 {
+				bit := gy*signatureSide + gx
+				sig[bit/64] |= 1 << (bit % 64)
+			}
+		}
+	}
+	return sig
+}
+
+// IoU returns the intersection over union of two signatures (1 means identical)
+func IoU(a, b MaskSignature) float64 {
+	inter, union := 0, 0
+	for i := range a {
+		inter += bits.OnesCount64(a[i] & b[i])
+		union += bits.OnesCount64(a[i] | b[i])
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+// AmbiguousGroups returns groups (sorted ids, each len >= 2) of pokemon whose signatures overlap by at least minIoU.
+// Grouping is transitive: if A~B and B~C all three share a group.
+func AmbiguousGroups(sigs map[int]MaskSignature, minIoU float64) [][]int {
+	ids := make([]int, 0, len(sigs))
+	for id := range sigs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	parent := make(map[int]int, len(ids))
+	var find func(int) int
+	find = func(x int) int {
+		if p, ok := parent[x]; ok && p != x {
+			parent[x] = find(p)
+			return parent[x]
+		}
+		return x
+	}
+	for i, a := range ids {
+		for _, b := range ids[i+1:] {
+			if IoU(sigs[a], sigs[b]) >= minIoU {
+				ra, rb := find(a), find(b)
+				if ra != rb {
+					parent[rb] = ra
+				}
+			}
+		}
+	}
+
+	byRoot := map[int][]int{}
+	for _, id := range ids {
+		r := find(id)
+		byRoot[r] = append(byRoot[r], id)
+	}
+	groups := make([][]int, 0)
+	for _, g := range byRoot {
+		if len(g) > 1 {
+			groups = append(groups, g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+// GetSignature returns the cached mask signature of a pokemon's official artwork.
+// The artwork itself is not kept, so signing every pokemon doesn't fill the sprite cache.
+func (c *Client) GetSignature(id int) (MaskSignature, error) {
+	c.mu.RLock()
+	if ce, ok := c.signCh[id]; ok && time.Now().Before(ce.exp) {
+		c.mu.RUnlock()
+		return ce.v, nil
+	}
+	c.mu.RUnlock()
+
+	img, err := c.downloadSprite(id, StyleOfficial)
+	if err != nil {
+		return MaskSignature{}, err
+	}
+	sig := Signature(img, DefaultMask)
+
+	c.mu.Lock()
+	c.signCh[id] = &cacheEntry[MaskSignature]{v: sig, exp: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return sig, nil
+}
+
+// ambiguityJSON is the output of cmd/ambiguity -json: the groups of near-identical silhouettes as id arrays
+//
+//go:embed ambiguity.json
+var ambiguityJSON []byte
+
+// embeddedGroups indexes ambiguity.json; nil while it was never generated (an empty array)
+func embeddedGroups() map[int][]int {
+	var groups [][]int
+	if err := json.Unmarshal(ambiguityJSON, &groups); err != nil {
+		panic(err)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	return indexGroups(groups)
+}
+
+// LoadAmbiguityGroups replaces the known groups with those in the JSON file at path (see WriteAmbiguityGroups)
+func (c *Client) LoadAmbiguityGroups(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var groups [][]int
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return err
+	}
+	groupOf := indexGroups(groups)
+	c.mu.Lock()
+	c.groupOf = groupOf
+	c.mu.Unlock()
+	return nil
+}
+
+// WriteAmbiguityGroups writes groups as a JSON array of id arrays, the format of ambiguity.json
+func WriteAmbiguityGroups(path string, groups [][]int) error {
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// indexGroups maps every member of groups to its group
+func indexGroups(groups [][]int) map[int][]int {
+	groupOf := make(map[int][]int)
+	for _, g := range groups {
+		for _, id := range g {
+			groupOf[id] = g
+		}
+	}
+	return groupOf
+}
+
+// AmbiguityGroup returns the group of near-identical silhouettes containing id (nil if none).
+// It never fetches: groups come from the embedded ambiguity.json or the last WarmAmbiguity.
+func (c *Client) AmbiguityGroup(id int) []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.groupOf[id]
+}
+
+// HasAmbiguityGroups reports whether the ambiguity groups are known (embedded, loaded or warmed), even if there
+// turned out to be none
+func (c *Client) HasAmbiguityGroups() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.groupOf != nil
+}
+
+// WarmAmbiguity signs the official artwork of ids, replaces the known groups with those overlapping by at least
+// minIoU and returns them with the ids that could not be signed. It downloads every artwork, so run it in the
+// background or from cmd/ambiguity, never while serving a request.
+func (c *Client) WarmAmbiguity(ids []int, minIoU float64) (groups [][]int, failed []int) {
+	sigs := make(map[int]MaskSignature, len(ids))
+	for _, id := range ids {
+		sig, err := c.GetSignature(id)
+		if err != nil {
+			failed = append(failed, id)
+			continue
+		}
+		sigs[id] = sig
+	}
+	groups = AmbiguousGroups(sigs, minIoU)
+
+	groupOf := indexGroups(groups)
+	c.mu.Lock()
+	c.groupOf = groupOf
+	c.mu.Unlock()
+	return groups, failed
+}
+
+// PokemonIDs lists the national dex ids of the given regions (all when empty) and, with forms, the ids of their
+// non-default varieties. Species that cannot be fetched contribute their default form only.
+func (c *Client) PokemonIDs(regions []string, forms bool) []int {
+	selected := map[string]bool{}
+	for _, k := range regions {
+		selected[k] = true
+	}
+	ids := make([]int, 0)
+	for _, rg := range Regions {
+		if len(selected) > 0 && !selected[rg.Key] {
+			continue
+		}
+		for id := rg.From; id <= rg.To; id++ {
+			ids = append(ids, id)
+			if !forms {
+				continue
+			}
+			sp, err := c.GetSpecies(id)
+			if err != nil {
+				continue
+			}
+			for _, v := range sp.Varieties {
+				if v.IsDefault {
+					continue
+				}
+				if formID, err := IDFromURL(v.Pokemon.URL); err == nil {
+					ids = append(ids, formID)
+				}
+			}
+		}
+	}
+	return ids
+}
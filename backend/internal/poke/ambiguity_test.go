@@ -0,0 +1,178 @@
+package poke
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// shapeImage draws opaque rects on a transparent w x h canvas
+func shapeImage(w, h int, rects ...image.Rectangle) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for _, r := range rects {
+		draw.Draw(img, r, image.NewUniform(color.NRGBA{A: 255}), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+// discImage draws an opaque disc of radius r centred at (cx, cy)
+func discImage(w, h int, cx, cy, r float64) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) <= r {
+				img.Pix[y*img.Stride+x*4+3] = 255
+			}
+		}
+	}
+	return img
+}
+
+func TestSignatureKeepsAspectRatio(t *testing.T) {
+	wide := Signature(shapeImage(200, 200, image.Rect(25, 95, 175, 105)), SilhouetteOptions{})
+	tall := Signature(shapeImage(200, 200, image.Rect(95, 25, 105, 175)), SilhouetteOptions{})
+	square := Signature(shapeImage(200, 200, image.Rect(70, 70, 130, 130)), SilhouetteOptions{})
+
+	for _, c := range []struct {
+		name string
+		a, b MaskSignature
+	}{
+		{"wide/tall", wide, tall},
+		{"wide/square", wide, square},
+		{"tall/square", tall, square},
+	} {
+		if iou := IoU(c.a, c.b); iou > 0.5 {
+			t.Errorf("%s: IoU = %.2f, want distinct silhouettes", c.name, iou)
+		}
+	}
+}
+
+func TestSignatureIgnoresPositionAndScale(t *testing.T) {
+	base := Signature(discImage(200, 200, 100, 100, 40), SilhouetteOptions{})
+	for _, c := range []struct {
+		name string
+		img  image.Image
+	}{
+		{"moved", discImage(200, 200, 60, 130, 40)},
+		{"smaller", discImage(120, 120, 60, 60, 24)},
+		{"larger", discImage(400, 300, 200, 150, 120)},
+	} {
+		if iou := IoU(base, Signature(c.img, SilhouetteOptions{})); iou < 0.95 {
+			t.Errorf("%s disc: IoU = %.2f, want the same silhouette", c.name, iou)
+		}
+	}
+
+	// a wide shape is centred in its square, so it keeps empty rows above and below
+	wide := Signature(shapeImage(100, 100, image.Rect(0, 40, 100, 60)), SilhouetteOptions{})
+	if wide.bit(0, 0) || !wide.bit(0, signatureSide/2) || wide.bit(signatureSide-1, signatureSide-1) {
+		t.Error("wide bar is not centred vertically in its signature")
+	}
+}
+
+func TestSignatureEmpty(t *testing.T) {
+	if sig := Signature(shapeImage(50, 50), SilhouetteOptions{}); sig != (MaskSignature{}) {
+		t.Error("transparent artwork has a non-empty signature")
+	}
+}
+
+func (s MaskSignature) bit(x, y int) bool {
+	b := y*signatureSide + x
+	return s[b/64]&(1<<(b%64)) != 0
+}
+
+func TestIoU(t *testing.T) {
+	var full, left, right, empty MaskSignature
+	for i := range full {
+		full[i] = ^uint64(0)
+		if i%2 == 0 {
+			left[i] = ^uint64(0) // the first half of every grid row
+		} else {
+			right[i] = ^uint64(0)
+		}
+	}
+	for _, c := range []struct {
+		name string
+		a, b MaskSignature
+		want float64
+	}{
+		{"identical", full, full, 1},
+		{"half", full, left, 0.5},
+		{"disjoint", left, right, 0},
+		{"both empty", empty, empty, 0},
+		{"one empty", full, empty, 0},
+	} {
+		if got := IoU(c.a, c.b); got != c.want {
+			t.Errorf("%s: IoU = %v, want %v", c.name, got, c.want)
+		}
+		if got := IoU(c.b, c.a); got != c.want {
+			t.Errorf("%s: IoU is not symmetric", c.name)
+		}
+	}
+}
+
+func TestAmbiguousGroups(t *testing.T) {
+	sig := func(bits ...int) MaskSignature {
+		var s MaskSignature
+		for _, b := range bits {
+			s[b/64] |= 1 << (b % 64)
+		}
+		return s
+	}
+	sigs := map[int]MaskSignature{
+		// 1~2 and 2~3 overlap by 2/4, 1 and 3 only by 1/5: the group is chained through 2
+		1:  sig(0, 1, 2),
+		2:  sig(1, 2, 3),
+		3:  sig(2, 3, 4),
+		10: sig(100, 101),
+		11: sig(100, 101),
+		20: sig(500), // alone
+		30: sig(),    // empty signatures never match
+		31: sig(),
+	}
+	got := AmbiguousGroups(sigs, 0.5)
+	want := [][]int{{1, 2, 3}, {10, 11}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %v, want %v", got, want)
+	}
+
+	if got := AmbiguousGroups(sigs, 0.9); !reflect.DeepEqual(got, [][]int{{10, 11}}) {
+		t.Errorf("groups at 0.9 = %v, want [[10 11]]", got)
+	}
+	if got := AmbiguousGroups(nil, 0.9); len(got) != 0 {
+		t.Errorf("groups of nothing = %v", got)
+	}
+}
+
+func TestAmbiguityGroupsFile(t *testing.T) {
+	c := NewClient(time.Minute)
+	if c.HasAmbiguityGroups() != (len(embeddedGroups()) > 0) {
+		t.Error("HasAmbiguityGroups disagrees with the embedded groups")
+	}
+
+	path := filepath.Join(t.TempDir(), "ambiguity.json")
+	if err := c.LoadAmbiguityGroups(path); err == nil {
+		t.Fatal("loaded a missing file")
+	}
+	if err := WriteAmbiguityGroups(path, [][]int{{25, 10080}, {201, 10001}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadAmbiguityGroups(path); err != nil {
+		t.Fatal(err)
+	}
+	if !c.HasAmbiguityGroups() || !reflect.DeepEqual(c.AmbiguityGroup(10080), []int{25, 10080}) || c.AmbiguityGroup(1) != nil {
+		t.Errorf("group of 10080 = %v, of 1 = %v", c.AmbiguityGroup(10080), c.AmbiguityGroup(1))
+	}
+
+	// no groups found is still a result
+	if err := WriteAmbiguityGroups(path, [][]int{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadAmbiguityGroups(path); err != nil || !c.HasAmbiguityGroups() {
+		t.Errorf("empty groups file: err %v, known %v", err, c.HasAmbiguityGroups())
+	}
+}
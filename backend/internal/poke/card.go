@@ -0,0 +1,237 @@
+package poke
+
+import (
+	"embed"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Result card dimensions match the common Open Graph image size
+const (
+	CardWidth  = 1200
+	CardHeight = 630
+)
+
+// Label is a text shown in Japanese when the card fonts can render it, English otherwise
+type Label struct {
+	JP string
+	EN string
+}
+
+// ResultCard is the content of a shareable result image
+type ResultCard struct {
+	Artwork  image.Image
+	Name     Label
+	Types    []Label
+	Region   Label
+	Solved   bool
+	TimedOut bool
+	Missed   bool // a wrong guess ended an endless streak
+	Elapsed  time.Duration
+	Guesses  int
+	Hints    int
+}
+
+// fontFS holds the CJK fonts built by fonts/subset.sh
+//
+//go:embed fonts
+var fontFS embed.FS
+
+var (
+	cardMu sync.RWMutex
+	// the first font with a glyph wins: Go for latin text, then the embedded CJK fonts
+	cardFonts = append([]*opentype.Font{mustParse(goregular.TTF)}, embeddedFonts()...)
+	cardBold  = mustParse(gobold.TTF)
+)
+
+// embeddedFonts parses every font in fontFS
+func embeddedFonts() []*opentype.Font {
+	entries, err := fontFS.ReadDir("fonts")
+	if err != nil {
+		panic(err)
+	}
+	var out []*opentype.Font
+	for _, e := range entries {
+		if ext := strings.ToLower(path.Ext(e.Name())); ext != ".otf" && ext != ".ttf" {
+			continue
+		}
+		data, err := fontFS.ReadFile("fonts/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, mustParse(data))
+	}
+	return out
+}
+
+// HasJapaneseFont reports whether card text such as Japanese names can be drawn in Japanese
+func HasJapaneseFont() bool {
+	fs, err := faces(12, false)
+	if err != nil {
+		return false
+	}
+	return pick(fs, Label{JP: "ピカチュウ", EN: "-"}) != "-"
+}
+
+func mustParse(data []byte) *opentype.Font {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// LoadCardFont adds a TrueType/OpenType font ahead of the embedded ones, e.g. to use a different CJK typeface.
+// Without any CJK font Japanese labels fall back to their English text.
+func LoadCardFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	cardMu.Lock()
+	defer cardMu.Unlock()
+	cardFonts = append([]*opentype.Font{f}, cardFonts...)
+	return nil
+}
+
+// faces builds the font chain at size px; bold puts Go Bold first for latin text.
+// Faces hold glyph buffers, so they are created per render rather than shared.
+func faces(size float64, bold bool) ([]font.Face, error) {
+	cardMu.RLock()
+	srcs := cardFonts
+	cardMu.RUnlock()
+	if bold {
+		srcs = append([]*opentype.Font{cardBold}, srcs...)
+	}
+
+	fs := make([]font.Face, 0, len(srcs))
+	for _, f := range srcs {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, face)
+	}
+	return fs, nil
+}
+
+// pick returns l.JP if every rune has a glyph in fs, else l.EN
+func pick(fs []font.Face, l Label) string {
+	if l.JP == "" {
+		return l.EN
+	}
+	for _, r := range l.JP {
+		found := false
+		for _, f := range fs {
+			if _, ok := f.GlyphAdvance(r); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return l.EN
+		}
+	}
+	return l.JP
+}
+
+// drawText draws s with its baseline at (x, y), taking each rune from the first face that has it
+func drawText(dst draw.Image, fs []font.Face, x, y int, c color.Color, s string) {
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Dot: fixed.P(x, y)}
+	for _, r := range s {
+		d.Face = fs[len(fs)-1]
+		for _, f := range fs {
+			if _, ok := f.GlyphAdvance(r); ok {
+				d.Face = f
+				break
+			}
+		}
+		d.DrawString(string(r))
+	}
+}
+
+// RenderCard draws a result card as PNG
+func RenderCard(c ResultCard) ([]byte, error) {
+	dst := image.NewNRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.NRGBA{R: 0xf4, G: 0xf1, B: 0xea, A: 255}), image.Point{}, draw.Src)
+
+	// artwork panel on the left
+	panel := image.Rect(35, 35, 35+560, 35+560)
+	draw.Draw(dst, panel, image.NewUniform(color.White), image.Point{}, draw.Src)
+	if c.Artwork != nil {
+		art := Resize(c.Artwork, 520)
+		off := panel.Min.Add(image.Pt((560-art.Rect.Dx())/2, (560-art.Rect.Dy())/2))
+		draw.Draw(dst, art.Rect.Add(off), art, image.Point{}, draw.Over)
+	}
+
+	title, err := faces(64, true)
+	if err != nil {
+		return nil, err
+	}
+	body, err := faces(34, false)
+	if err != nil {
+		return nil, err
+	}
+	small, err := faces(24, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ink := color.NRGBA{R: 0x22, G: 0x22, B: 0x22, A: 255}
+	muted := color.NRGBA{R: 0x66, G: 0x66, B: 0x66, A: 255}
+	x := 640
+
+	status, statusColor := "SOLVED!", color.NRGBA{R: 0x2e, G: 0x8b, B: 0x57, A: 255}
+	if !c.Solved {
+		status, statusColor = "GAVE UP", color.NRGBA{R: 0xc0, G: 0x39, B: 0x2b, A: 255}
+		if c.TimedOut {
+			status = "TIME UP"
+		}
+		if c.Missed {
+			status = "MISSED"
+		}
+	}
+	drawText(dst, body, x, 90, statusColor, status)
+
+	name := pick(title, c.Name)
+	drawText(dst, title, x, 170, ink, name)
+	if name != c.Name.EN {
+		drawText(dst, body, x, 220, muted, c.Name.EN)
+	}
+
+	types := ""
+	for i, t := range c.Types {
+		if i > 0 {
+			types += " / "
+		}
+		types += pick(body, t)
+	}
+	drawText(dst, body, x, 290, ink, types)
+	drawText(dst, body, x, 340, ink, pick(body, c.Region))
+
+	drawText(dst, body, x, 430, ink, fmt.Sprintf("Time: %.1fs", c.Elapsed.Seconds()))
+	drawText(dst, body, x, 480, ink, fmt.Sprintf("Guesses: %d", c.Guesses))
+	drawText(dst, body, x, 530, ink, fmt.Sprintf("Hints: %d", c.Hints))
+
+	drawText(dst, small, x, 595, muted, "Pokémon Silhouette Quiz")
+
+	return EncodePNG(dst)
+}
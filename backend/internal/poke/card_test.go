@@ -0,0 +1,68 @@
+package poke
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestRenderCard(t *testing.T) {
+	data, err := RenderCard(ResultCard{Name: Label{JP: "ピカチュウ", EN: "Pikachu"}, Types: []Label{{JP: "でんき", EN: "Electric"}}, Region: Label{JP: "カントー", EN: "Kanto"}, Solved: true, Guesses: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != CardWidth || b.Dy() != CardHeight {
+		t.Errorf("card is %v, want %dx%d", b, CardWidth, CardHeight)
+	}
+}
+
+// TestCardJapanese needs the embedded font from fonts/subset.sh
+func TestCardJapanese(t *testing.T) {
+	if len(embeddedFonts()) == 0 {
+		t.Skip("fonts/NotoSansJP-subset.otf is not generated; run fonts/subset.sh")
+	}
+	if !HasJapaneseFont() {
+		t.Fatal("HasJapaneseFont() = false with an embedded font")
+	}
+
+	fs, err := faces(34, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, jp := range []string{"ピカチュウ", "ニドラン♀", "ポリゴンＺ", "でんき", "ほのお", "カントー", "パルデア", "たねポケモン"} {
+		if got := pick(fs, Label{JP: jp, EN: "-"}); got != jp {
+			t.Errorf("%s falls back to English", jp)
+		}
+	}
+
+	// without an English fallback, a card only has text where the Japanese glyphs were drawn
+	blank := decodeCard(t, ResultCard{})
+	jp := decodeCard(t, ResultCard{Name: Label{JP: "ピカチュウ"}, Types: []Label{{JP: "でんき"}}})
+	if bytes.Equal(blank.Pix, jp.Pix) {
+		t.Error("ピカチュウ / でんき drew nothing")
+	}
+}
+
+func decodeCard(t *testing.T, c ResultCard) *image.NRGBA {
+	t.Helper()
+	data, err := RenderCard(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := image.NewNRGBA(img.Bounds())
+	for y := 0; y < CardHeight; y++ {
+		for x := 0; x < CardWidth; x++ {
+			out.Set(x, y, img.At(x, y))
+		}
+	}
+	return out
+}
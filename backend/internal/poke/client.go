@@ -7,6 +7,8 @@ import (
 	_ "image/png"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -19,8 +21,24 @@ type Client struct {
 
 	mu        sync.RWMutex
 	pokemonCh map[int]*cacheEntry[Pokemon]
-	spriteCh  map[int]*cacheEntry[image.Image]
+	spriteCh  map[spriteKey]*cacheEntry[image.Image]
 	speciesCh map[int]*cacheEntry[Species]
+	signCh    map[int]*cacheEntry[MaskSignature]
+	groupOf   map[int][]int // ambiguity group of each grouped pokemon id, see AmbiguityGroup
+
+	renders *renderCache
+}
+
+// spriteKey identifies a decoded sprite by pokemon and style
+type spriteKey struct {
+	id    int
+	style Style
+}
+
+// renderKey identifies an encoded image by pokemon and render options
+type renderKey struct {
+	id   int
+	opts RenderOptions
 }
 
 type cacheEntry[T any] struct {
@@ -33,6 +51,18 @@ type Pokemon struct {
 	Name    string  `json:"name"`
 	Types   []PType `json:"types"`
 	Sprites Sprites `json:"sprites"`
+	Species struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"species"`
+}
+
+// SpeciesID returns the national dex id of p's species, which differs from p.ID for forms (ids >= 10000)
+func (p Pokemon) SpeciesID() int {
+	if id, err := IDFromURL(p.Species.URL); err == nil {
+		return id
+	}
+	return p.ID
 }
 
 type PType struct {
@@ -42,16 +72,8 @@ type PType struct {
 	} `json:"type"`
 }
 
-type Sprites struct {
-	Other struct {
-		OfficialArtwork struct {
-			FrontDefault string `json:"front_default"`
-		} `json:"official-artwork"`
-	} `json:"other"`
-}
-
 func NewClient(ttl time.Duration) *Client {
-	return &Client{http: &http.Client{Timeout: 15 * time.Second}, ttl: ttl, pokemonCh: make(map[int]*cacheEntry[Pokemon]), spriteCh: make(map[int]*cacheEntry[image.Image]), speciesCh: make(map[int]*cacheEntry[Species])}
+	return &Client{http: &http.Client{Timeout: 15 * time.Second}, ttl: ttl, pokemonCh: make(map[int]*cacheEntry[Pokemon]), spriteCh: make(map[spriteKey]*cacheEntry[image.Image]), speciesCh: make(map[int]*cacheEntry[Species]), signCh: make(map[int]*cacheEntry[MaskSignature]), groupOf: embeddedGroups(), renders: newRenderCache(ttl, RenderCacheBytes)}
 }
 
 func (c *Client) GetPokemon(id int) (Pokemon, error) {
@@ -90,22 +112,55 @@ func (c *Client) GetPokemon(id int) (Pokemon, error) {
 	return p, nil
 }
 
+// GetOfficialArtwork returns the decoded official artwork
 func (c *Client) GetOfficialArtwork(id int) (image.Image, error) {
+	return c.GetArtwork(id, StyleOfficial)
+}
+
+// GetArtwork returns the decoded sprite of the given style, falling back along style.Fallbacks when it is missing
+func (c *Client) GetArtwork(id int, style Style) (image.Image, error) {
+	var lastErr error
+	for _, s := range style.Fallbacks() {
+		img, err := c.getSprite(id, s)
+		if err == nil {
+			return img, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (c *Client) getSprite(id int, style Style) (image.Image, error) {
+	key := spriteKey{id: id, style: style}
 	c.mu.RLock()
-	if ce, ok := c.spriteCh[id]; ok && time.Now().Before(ce.exp) {
+	if ce, ok := c.spriteCh[key]; ok && time.Now().Before(ce.exp) {
 		c.mu.RUnlock()
 		return ce.v, nil
 	}
 	c.mu.RUnlock()
 
+	img, err := c.downloadSprite(id, style)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.spriteCh[key] = &cacheEntry[image.Image]{v: img, exp: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return img, nil
+}
+
+// downloadSprite fetches and decodes a sprite without caching it
+func (c *Client) downloadSprite(id int, style Style) (image.Image, error) {
 	p, err := c.GetPokemon(id)
 	if err != nil {
 		return nil, err
 	}
 
-	art := p.Sprites.Other.OfficialArtwork.FrontDefault
+	art := p.Sprites.URL(style, GenerationOf(p.SpeciesID()))
 	if art == "" {
-		return nil, fmt.Errorf("no artwork")
+		return nil, fmt.Errorf("no %s artwork", style)
 	}
 
 	resp, err := c.http.Get(art)
@@ -114,16 +169,35 @@ func (c *Client) GetOfficialArtwork(id int) (image.Image, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("sprite status %d", resp.StatusCode)
+	}
+
 	img, _, err := image.Decode(resp.Body)
+	return img, err
+}
+
+// Render returns the artwork of id in opts.Style rendered with opts and encoded in opts.Format.
+// Encoded bytes are cached per (id, opts), up to RenderCacheBytes in total, so repeated requests skip rendering
+// and encoding.
+func (c *Client) Render(id int, opts RenderOptions) ([]byte, error) {
+	key := renderKey{id: id, opts: opts}
+	if data, ok := c.renders.get(key); ok {
+		return data, nil
+	}
+
+	img, err := c.GetArtwork(id, opts.Style)
 	if err != nil {
 		return nil, err
 	}
 
-	c.mu.Lock()
-	c.spriteCh[id] = &cacheEntry[image.Image]{v: img, exp: time.Now().Add(c.ttl)}
-	c.mu.Unlock()
+	data, err := render(img, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	return img, nil
+	c.renders.put(key, data)
+	return data, nil
 }
 
 // Species (subset) for name localization
@@ -141,6 +215,52 @@ type Species struct {
 			URL  string `json:"url"`
 		} `json:"pokemon"`
 	} `json:"varieties"`
+	Genera []struct {
+		Genus    string `json:"genus"`
+		Language struct {
+			Name string `json:"name"`
+		} `json:"language"`
+	} `json:"genera"`
+	FlavorTextEntries []struct {
+		FlavorText string `json:"flavor_text"`
+		Language   struct {
+			Name string `json:"name"`
+		} `json:"language"`
+	} `json:"flavor_text_entries"`
+}
+
+// IDFromURL extracts the trailing id of a PokeAPI resource URL (e.g. https://pokeapi.co/api/v2/pokemon/10034/)
+func IDFromURL(u string) (int, error) {
+	parts := strings.Split(strings.TrimSuffix(u, "/"), "/")
+	return strconv.Atoi(parts[len(parts)-1])
+}
+
+// Genus returns the species category (e.g. "たねポケモン"), preferring ja-Hrkt, then ja, then en
+func (sp Species) Genus() string {
+	byLang := map[string]string{}
+	for _, g := range sp.Genera {
+		byLang[g.Language.Name] = g.Genus
+	}
+	return firstOf(byLang, "ja-Hrkt", "ja", "en")
+}
+
+// FlavorText returns the latest pokedex entry, preferring ja-Hrkt, then ja, then en
+func (sp Species) FlavorText() string {
+	byLang := map[string]string{}
+	for _, f := range sp.FlavorTextEntries {
+		// entries are listed oldest game first; keep the latest, normalizing the game's line breaks
+		byLang[f.Language.Name] = strings.Join(strings.Fields(f.FlavorText), " ")
+	}
+	return firstOf(byLang, "ja-Hrkt", "ja", "en")
+}
+
+func firstOf(m map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := m[k]; v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 func (c *Client) GetSpecies(id int) (Species, error) {
@@ -0,0 +1,15 @@
+#!/bin/sh
+# Builds NotoSansJP-subset.otf, the CJK font embedded for result cards.
+# The subset keeps ASCII, Latin-1, kana, CJK punctuation and full-width forms: every pokemon name (ja-Hrkt),
+# type and region shown on a card is written with these. Requires curl and fonttools (pip install fonttools).
+set -eu
+cd "$(dirname "$0")"
+
+src=NotoSansJP-Regular.otf
+curl -fsSL -o "$src" https://github.com/notofonts/noto-cjk/raw/main/Sans/SubsetOTF/JP/NotoSansJP-Regular.otf
+pyftsubset "$src" \
+	--unicodes="U+0020-007E,U+00A0-00FF,U+2010-2027,U+2640,U+2642,U+3000-303F,U+3040-309F,U+30A0-30FF,U+FF01-FF9F" \
+	--layout-features='*' --output-file=NotoSansJP-subset.otf
+rm "$src"
+# the OFL requires the licence to travel with the font
+curl -fsSL -o OFL.txt https://github.com/notofonts/noto-cjk/raw/main/Sans/LICENSE
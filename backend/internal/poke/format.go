@@ -0,0 +1,163 @@
+package poke
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Format is an output encoding for rendered images
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatJPEG Format = "jpeg"
+	FormatGIF  Format = "gif"
+	FormatSVG  Format = "svg"
+)
+
+// Size limits (longest edge, px) accepted by Resize callers
+const (
+	MinSize = 32
+	MaxSize = 1024
+)
+
+var ErrUnknownFormat = errors.New("unknown image format")
+var ErrInvalidSize = fmt.Errorf("size must be between %d and %d", MinSize, MaxSize)
+
+// ParseFormat validates a format name; empty means PNG
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "png":
+		return FormatPNG, nil
+	case "jpeg", "jpg":
+		return FormatJPEG, nil
+	case "gif":
+		return FormatGIF, nil
+	case "svg":
+		return FormatSVG, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// ContentType returns the MIME type for f
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatGIF:
+		return "image/gif"
+	case FormatSVG:
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ValidSize reports whether size is 0 (original) or within [MinSize, MaxSize]
+func ValidSize(size int) bool {
+	return size == 0 || (size >= MinSize && size <= MaxSize)
+}
+
+// Resize scales src so its longest edge equals size, keeping the aspect ratio.
+// Catmull-Rom resampling keeps edges smooth when shrinking the large official artwork.
+func Resize(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(fitRect(b, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, xdraw.Src, nil)
+	return dst
+}
+
+// ResizeNearest scales pixel art so its longest edge equals size without blurring pixels
+func ResizeNearest(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(fitRect(b, size))
+	xdraw.NearestNeighbor.Scale(dst, dst.Bounds(), src, b, xdraw.Src, nil)
+	return dst
+}
+
+// fitRect returns a rectangle at the origin whose longest edge is size, keeping b's aspect ratio
+func fitRect(b image.Rectangle, size int) image.Rectangle {
+	w, h := b.Dx(), b.Dy()
+	if w >= h {
+		return image.Rect(0, 0, size, max(1, h*size/w))
+	}
+	return image.Rect(0, 0, max(1, w*size/h), size)
+}
+
+// Encode encodes img in format f. bg fills transparent areas for formats without alpha (JPEG).
+func Encode(img image.Image, f Format, bg color.NRGBA) ([]byte, error) {
+	switch f {
+	case FormatJPEG:
+		return encodeJPEG(img, bg)
+	case FormatGIF:
+		return encodeGIF(img)
+	case FormatSVG:
+		return encodeSVG(img)
+	}
+	return EncodePNG(img)
+}
+
+func encodeJPEG(img image.Image, bg color.NRGBA) ([]byte, error) {
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	xdraw.Draw(dst, b, image.NewUniform(bg), image.Point{}, xdraw.Src)
+	xdraw.Draw(dst, b, img, b.Min, xdraw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeGIF quantizes img with paletted and encodes a single frame
+func encodeGIF(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, paletted(img), nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// paletted quantizes to Plan9 with index 0 reserved for transparency
+func paletted(img image.Image) *image.Paletted {
+	b := img.Bounds()
+	pal := make(color.Palette, 0, 256)
+	pal = append(pal, color.Transparent)
+	pal = append(pal, palette.Plan9[:255]...)
+
+	dst := image.NewPaletted(b, pal)
+	xdraw.FloydSteinberg.Draw(dst, b, img, b.Min)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			_, _, _, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				dst.SetColorIndex(x, y, 0)
+			}
+		}
+	}
+	return dst
+}
+
+// encodeSVG wraps a PNG rendering in an SVG document so it can be sized by CSS.
+// Silhouettes use TraceSilhouette instead for a true vector path.
+func encodeSVG(img image.Image) ([]byte, error) {
+	data, err := EncodePNG(img)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`, b.Dx(), b.Dy(), b.Dx(), b.Dy())
+	fmt.Fprintf(&buf, `<image width="%d" height="%d" href="data:image/png;base64,%s"/></svg>`, b.Dx(), b.Dy(), base64.StdEncoding.EncodeToString(data))
+	return buf.Bytes(), nil
+}
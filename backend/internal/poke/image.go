@@ -4,26 +4,193 @@ import (
 	"bytes"
 	"image"
 	"image/color"
-	"image/draw"
 	"image/png"
 )
 
+// RenderOptions selects how an artwork is rendered before encoding.
+// It is comparable so it can be used as part of a cache key.
+type RenderOptions struct {
+	Style      Style // empty means official artwork
+	Silhouette bool
+	Mask       SilhouetteOptions // applied when Silhouette or Reveal is set
+	Reveal     bool              // animated silhouette-to-artwork GIF; Format is ignored
+	Format     Format            // empty means PNG
+	Size       int               // longest edge in px; 0 keeps the original size
+	Background color.NRGBA       // fill for formats without alpha
+}
+
+// pixelArtSize is the default longest edge for pixel-art styles, which are only ~96px
+const pixelArtSize = 384
+
+// render applies opts to a decoded artwork and encodes the result
+func render(img image.Image, opts RenderOptions) ([]byte, error) {
+	scale := Resize
+	if opts.Style.PixelArt() {
+		scale = ResizeNearest
+		if opts.Size == 0 {
+			opts.Size = pixelArtSize
+		}
+	}
+
+	switch {
+	case opts.Reveal:
+		if opts.Size > 0 {
+			img = scale(img, opts.Size)
+		}
+		return RevealGIF(img, opts.Mask)
+	case opts.Silhouette && opts.Format == FormatSVG:
+		// vector silhouettes are traced at full resolution and scaled by the SVG viewBox
+		return TraceSilhouette(img, opts.Size, opts.Mask), nil
+	}
+
+	if opts.Size > 0 {
+		img = scale(img, opts.Size)
+	}
+	if opts.Silhouette {
+		img = Silhouette(img, opts.Mask)
+	}
+	return Encode(img, opts.Format, opts.Background)
+}
+
+// SilhouetteOptions tunes how artwork alpha becomes a silhouette.
+// The zero value treats every pixel with alpha > 0 as solid, like ToSilhouette always has.
+type SilhouetteOptions struct {
+	Threshold uint8 // pixels with alpha <= Threshold are background (drops faint shadows and fringe)
+	AntiAlias bool  // keep the original alpha on solid pixels for smooth edges
+	MinSpeck  int   // solid regions smaller than this many pixels are removed
+}
+
+// DefaultMask ignores faint shadows/fringe and drops isolated specks
+var DefaultMask = SilhouetteOptions{Threshold: 32, MinSpeck: 16}
+
 // ToSilhouette converts an image to a black silhouette with transparent background
 func ToSilhouette(src image.Image) ([]byte, error) {
+	return EncodePNG(SilhouetteImage(src))
+}
+
+// SilhouetteImage returns a black silhouette of src with transparent background, treating every pixel with
+// alpha > 0 as solid (the zero SilhouetteOptions, not DefaultMask)
+func SilhouetteImage(src image.Image) *image.NRGBA {
+	return Silhouette(src, SilhouetteOptions{})
+}
+
+// Silhouette returns a black silhouette of src with transparent background
+func Silhouette(src image.Image, o SilhouetteOptions) *image.NRGBA {
 	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	alpha := alphaPlane(src)
+
+	solid := make([]bool, len(alpha))
+	for i, a := range alpha {
+		solid[i] = a > o.Threshold
+	}
+	if o.MinSpeck > 1 {
+		removeSpecks(solid, w, h, o.MinSpeck)
+	}
+
 	dst := image.NewNRGBA(b)
-	draw.Draw(dst, b, image.Transparent, image.Point{}, draw.Src)
-	for y := b.Min.Y; y < b.Max.Y; y++ {
-		for x := b.Min.X; x < b.Max.X; x++ {
-			_, _, _, a := src.At(x, y).RGBA()
-			if a > 0 { // if not fully transparent
-				dst.Set(x, y, color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+	for y := 0; y < h; y++ {
+		drow := dst.Pix[y*dst.Stride : y*dst.Stride+w*4]
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			if !solid[i] {
+				continue
+			}
+			if o.AntiAlias {
+				drow[x*4+3] = alpha[i]
+			} else {
+				drow[x*4+3] = 255
+			}
+		}
+	}
+	return dst
+}
+
+// alphaPlane returns the 8-bit alpha of src row by row (len = width*height).
+// NRGBA, RGBA and Paletted sources are read directly from their pixel buffers.
+func alphaPlane(src image.Image) []uint8 {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := make([]uint8, w*h)
+	switch s := src.(type) {
+	case *image.NRGBA:
+		alphaFromPix(out, s.Pix, s.Stride, w, h)
+	case *image.RGBA:
+		alphaFromPix(out, s.Pix, s.Stride, w, h)
+	case *image.Paletted:
+		pal := make([]uint8, 256)
+		for i, c := range s.Palette {
+			_, _, _, a := c.RGBA()
+			pal[i] = uint8(a >> 8)
+		}
+		for y := 0; y < h; y++ {
+			for x, idx := range s.Pix[y*s.Stride : y*s.Stride+w] {
+				out[y*w+x] = pal[idx]
+			}
+		}
+	default:
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				_, _, _, a := src.At(b.Min.X+x, b.Min.Y+y).RGBA()
+				out[y*w+x] = uint8(a >> 8)
 			}
 		}
 	}
+	return out
+}
+
+func alphaFromPix(out, pix []byte, stride, w, h int) {
+	for y := 0; y < h; y++ {
+		row := pix[y*stride : y*stride+w*4]
+		for x := 0; x < w; x++ {
+			out[y*w+x] = row[x*4+3]
+		}
+	}
+}
+
+// removeSpecks clears 8-connected solid regions with fewer than minSize pixels
+func removeSpecks(solid []bool, w, h, minSize int) {
+	seen := make([]bool, len(solid))
+	stack := make([]int, 0, 64)
+	region := make([]int, 0, 64)
+	for start := range solid {
+		if !solid[start] || seen[start] {
+			continue
+		}
+		region = region[:0]
+		stack = append(stack[:0], start)
+		seen[start] = true
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			region = append(region, i)
+			x, y := i%w, i/w
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					j := ny*w + nx
+					if solid[j] && !seen[j] {
+						seen[j] = true
+						stack = append(stack, j)
+					}
+				}
+			}
+		}
+		if len(region) < minSize {
+			for _, i := range region {
+				solid[i] = false
+			}
+		}
+	}
+}
 
+// EncodePNG encodes img as PNG
+func EncodePNG(img image.Image) ([]byte, error) {
 	var buf bytes.Buffer
-	if err := png.Encode(&buf, dst); err != nil {
+	if err := png.Encode(&buf, img); err != nil {
 		return nil, err
 	}
 
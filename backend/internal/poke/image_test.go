@@ -0,0 +1,187 @@
+package poke
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// testArtwork returns a size x size NRGBA disc with a soft edge, like official artwork
+func testArtwork(size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	c, r := float64(size)/2, float64(size)*0.4
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-c, float64(y)-c
+			d := r - math.Sqrt(dx*dx+dy*dy) // distance inside the edge
+			switch {
+			case d >= 4:
+				img.SetNRGBA(x, y, color.NRGBA{R: 200, G: 80, B: 40, A: 255})
+			case d > 0:
+				img.SetNRGBA(x, y, color.NRGBA{R: 200, G: 80, B: 40, A: uint8(d * 63)})
+			}
+		}
+	}
+	return img
+}
+
+// silhouetteAtSet is the original per-pixel At/Set implementation, kept as the benchmark baseline
+func silhouetteAtSet(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(b)
+	draw.Draw(dst, b, image.Transparent, image.Point{}, draw.Src)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			_, _, _, a := src.At(x, y).RGBA()
+			if a > 0 {
+				dst.Set(x, y, color.NRGBA{A: 255})
+			}
+		}
+	}
+	return dst
+}
+
+func BenchmarkSilhouette(b *testing.B) {
+	src := testArtwork(475)
+	b.Run("AtSet", func(b *testing.B) {
+		for b.Loop() {
+			silhouetteAtSet(src)
+		}
+	})
+	b.Run("PixBuffer", func(b *testing.B) {
+		for b.Loop() {
+			Silhouette(src, SilhouetteOptions{})
+		}
+	})
+}
+
+func BenchmarkRenderCached(b *testing.B) {
+	c := NewClient(time.Hour)
+	opts := RenderOptions{Silhouette: true, Mask: DefaultMask}
+	data, err := render(testArtwork(475), opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	c.renders.put(renderKey{id: 25, opts: opts}, data)
+
+	b.ResetTimer()
+	for b.Loop() {
+		if _, err := c.Render(25, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSilhouetteMatchesAtSet(t *testing.T) {
+	src := testArtwork(64)
+	got, want := Silhouette(src, SilhouetteOptions{}), silhouetteAtSet(src)
+	for i := range want.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			t.Fatalf("pixel byte %d = %d, want %d", i, got.Pix[i], want.Pix[i])
+		}
+	}
+}
+
+// testdata/artwork.png is a 64x64 disc with a 3px soft edge, a faint (alpha 20) drop shadow,
+// specks of 1, 4 and 9 px and a 25 px block
+var silhouetteGolden = []struct {
+	name string
+	opts SilhouetteOptions
+}{
+	{"silhouette-zero", SilhouetteOptions{}},
+	{"silhouette-threshold", SilhouetteOptions{Threshold: 32}},
+	{"silhouette-aa", SilhouetteOptions{Threshold: 32, AntiAlias: true}},
+	{"silhouette-speck", DefaultMask},
+}
+
+func TestSilhouetteGolden(t *testing.T) {
+	src := readPNG(t, "artwork.png")
+	for _, tc := range silhouetteGolden {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Silhouette(src, tc.opts)
+			path := filepath.Join("testdata", tc.name+".png")
+			if *update {
+				data, err := EncodePNG(got)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := readPNG(t, tc.name+".png")
+			if got.Bounds() != want.Bounds() {
+				t.Fatalf("bounds = %v, want %v", got.Bounds(), want.Bounds())
+			}
+			b := want.Bounds()
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					if g, w := got.NRGBAAt(x, y), color.NRGBAModel.Convert(want.At(x, y)).(color.NRGBA); g != w {
+						t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, g, w)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestSilhouetteOptions pins what each option does to the golden input, so an -update can't silently bless a regression
+func TestSilhouetteOptions(t *testing.T) {
+	src := readPNG(t, "artwork.png")
+	alphaAt := func(img *image.NRGBA, x, y int) uint8 { return img.NRGBAAt(x, y).A }
+	shadow, lone, block2, block3, block5 := [2]int{20, 55}, [2]int{3, 3}, [2]int{56, 4}, [2]int{3, 59}, [2]int{58, 58}
+	edge := [2]int{31, 13} // inside the soft edge
+
+	zero := Silhouette(src, SilhouetteOptions{})
+	for _, p := range [][2]int{shadow, lone, edge, {31, 31}} {
+		if alphaAt(zero, p[0], p[1]) != 255 {
+			t.Errorf("zero options: (%d,%d) should be solid", p[0], p[1])
+		}
+	}
+
+	th := Silhouette(src, SilhouetteOptions{Threshold: 32})
+	if alphaAt(th, shadow[0], shadow[1]) != 0 {
+		t.Error("threshold 32 should drop the alpha-20 shadow")
+	}
+	if alphaAt(th, edge[0], edge[1]) != 255 {
+		t.Error("threshold 32 should keep the edge solid")
+	}
+
+	aa := Silhouette(src, SilhouetteOptions{Threshold: 32, AntiAlias: true})
+	if a := alphaAt(aa, edge[0], edge[1]); a == 0 || a == 255 {
+		t.Errorf("anti-aliased edge alpha = %d, want partial", a)
+	}
+
+	sp := Silhouette(src, DefaultMask)
+	for _, p := range [][2]int{lone, block2, block3} {
+		if alphaAt(sp, p[0], p[1]) != 0 {
+			t.Errorf("speck at (%d,%d) should be removed", p[0], p[1])
+		}
+	}
+	if alphaAt(sp, block5[0], block5[1]) != 255 {
+		t.Error("25px block should survive MinSpeck 16")
+	}
+}
+
+func readPNG(t *testing.T, name string) image.Image {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
@@ -24,3 +24,13 @@ var Regions = []Region{
 
 // ContainsNationalID returns true if the id is inside region range
 func (r Region) ContainsNationalID(id int) bool { return id >= r.From && id <= r.To }
+
+// GenerationOf returns the debut generation of a national dex id, or 0 (e.g. form ids)
+func GenerationOf(id int) int {
+	for _, r := range Regions {
+		if r.ContainsNationalID(id) {
+			return r.Generation
+		}
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package poke
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// RenderCacheBytes caps the encoded bytes Client keeps from Render
+const RenderCacheBytes = 64 << 20
+
+// renderCache is an LRU of encoded images bounded by total size; entries also expire after ttl
+type renderCache struct {
+	ttl      time.Duration
+	maxBytes int
+
+	mu    sync.Mutex
+	bytes int
+	m     map[renderKey]*list.Element
+	lru   *list.List // front = most recently used
+}
+
+type renderEntry struct {
+	key  renderKey
+	data []byte
+	exp  time.Time
+}
+
+func newRenderCache(ttl time.Duration, maxBytes int) *renderCache {
+	return &renderCache{ttl: ttl, maxBytes: maxBytes, m: make(map[renderKey]*list.Element), lru: list.New()}
+}
+
+func (c *renderCache) get(key renderKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.m[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*renderEntry)
+	if !time.Now().Before(e.exp) {
+		c.remove(el)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e.data, true
+}
+
+func (c *renderCache) put(key renderKey, data []byte) {
+	if len(data) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.m[key]; ok {
+		c.remove(el)
+	}
+	for c.bytes+len(data) > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+	c.m[key] = c.lru.PushFront(&renderEntry{key: key, data: data, exp: time.Now().Add(c.ttl)})
+	c.bytes += len(data)
+}
+
+// len returns the number of cached entries and their total size
+func (c *renderCache) len() (int, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len(), c.bytes
+}
+
+func (c *renderCache) remove(el *list.Element) {
+	e := el.Value.(*renderEntry)
+	c.lru.Remove(el)
+	delete(c.m, e.key)
+	c.bytes -= len(e.data)
+}
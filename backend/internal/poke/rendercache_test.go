@@ -0,0 +1,52 @@
+package poke
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newRenderCache(time.Hour, 10)
+	key := func(id int) renderKey { return renderKey{id: id} }
+
+	c.put(key(1), make([]byte, 4))
+	c.put(key(2), make([]byte, 4))
+	if _, ok := c.get(key(1)); !ok { // 1 becomes most recently used
+		t.Fatal("entry 1 missing")
+	}
+	c.put(key(3), make([]byte, 4)) // 12 bytes > 10: evicts 2
+
+	if _, ok := c.get(key(2)); ok {
+		t.Error("entry 2 should have been evicted")
+	}
+	for _, id := range []int{1, 3} {
+		if _, ok := c.get(key(id)); !ok {
+			t.Errorf("entry %d missing", id)
+		}
+	}
+	if n, size := c.len(); n != 2 || size != 8 {
+		t.Errorf("len = %d entries, %d bytes; want 2, 8", n, size)
+	}
+}
+
+func TestRenderCacheReplaceAndOversize(t *testing.T) {
+	c := newRenderCache(time.Hour, 10)
+	c.put(renderKey{id: 1}, make([]byte, 6))
+	c.put(renderKey{id: 1}, make([]byte, 3))
+	c.put(renderKey{id: 2}, make([]byte, 11)) // larger than the cap: not cached
+	if n, size := c.len(); n != 1 || size != 3 {
+		t.Errorf("len = %d entries, %d bytes; want 1, 3", n, size)
+	}
+}
+
+func TestRenderCacheExpires(t *testing.T) {
+	c := newRenderCache(time.Nanosecond, 10)
+	c.put(renderKey{id: 1}, []byte{1})
+	time.Sleep(time.Millisecond)
+	if _, ok := c.get(renderKey{id: 1}); ok {
+		t.Error("expired entry returned")
+	}
+	if n, size := c.len(); n != 0 || size != 0 {
+		t.Errorf("len = %d entries, %d bytes; want 0, 0", n, size)
+	}
+}
@@ -0,0 +1,55 @@
+package poke
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+)
+
+// Reveal animation timing (GIF delays are in 1/100 s)
+const (
+	revealFrames     = 12
+	revealFrameDelay = 8
+	revealHoldDelay  = 150
+)
+
+// RevealGIF renders an animated GIF fading from the black silhouette of src to its colour artwork.
+// The first frame matches Silhouette(src, o); first and last frames are held so the clip reads well when looped.
+func RevealGIF(src image.Image, o SilhouetteOptions) ([]byte, error) {
+	b := src.Bounds()
+	art := image.NewNRGBA(b)
+	draw.Draw(art, b, src, b.Min, draw.Src)
+	sil := Silhouette(art, o)
+
+	anim := &gif.GIF{}
+	for i := 0; i <= revealFrames; i++ {
+		// t in [0,256]: 0 is the silhouette, 256 the artwork
+		t := i * 256 / revealFrames
+		frame := image.NewNRGBA(b)
+		for j := 0; j < len(art.Pix); j += 4 {
+			if art.Pix[j+3] == 0 && sil.Pix[j+3] == 0 {
+				continue
+			}
+			frame.Pix[j] = uint8(int(art.Pix[j]) * t / 256)
+			frame.Pix[j+1] = uint8(int(art.Pix[j+1]) * t / 256)
+			frame.Pix[j+2] = uint8(int(art.Pix[j+2]) * t / 256)
+			// alpha moves from the silhouette mask to the artwork's real alpha
+			frame.Pix[j+3] = uint8((int(sil.Pix[j+3])*(256-t) + int(art.Pix[j+3])*t) / 256)
+		}
+
+		delay := revealFrameDelay
+		if i == 0 || i == revealFrames {
+			delay = revealHoldDelay
+		}
+		anim.Image = append(anim.Image, paletted(frame))
+		anim.Delay = append(anim.Delay, delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
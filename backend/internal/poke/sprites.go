@@ -0,0 +1,134 @@
+package poke
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sprites (subset) of the PokeAPI sprite families
+type Sprites struct {
+	FrontDefault string `json:"front_default"` // current-generation pixel sprite
+	FrontShiny   string `json:"front_shiny"`
+	Other        struct {
+		OfficialArtwork SpritePair `json:"official-artwork"`
+		Home            SpritePair `json:"home"`
+	} `json:"other"`
+	// Versions maps generation ("generation-i") to game ("red-blue") to its in-game sprites
+	Versions map[string]map[string]SpritePair `json:"versions"`
+}
+
+// SpritePair is a default/shiny sprite pair; either may be empty.
+// Generation I/II game sprites also carry a transparent-background variant.
+type SpritePair struct {
+	FrontDefault     string `json:"front_default"`
+	FrontShiny       string `json:"front_shiny"`
+	FrontTransparent string `json:"front_transparent"`
+}
+
+// Style selects which sprite family a quiz silhouettes
+type Style string
+
+const (
+	StyleOfficial      Style = "official"
+	StyleOfficialShiny Style = "official-shiny"
+	StyleHome          Style = "home"
+	StyleHomeShiny     Style = "home-shiny"
+	StylePixel         Style = "pixel"
+	StylePixelShiny    Style = "pixel-shiny"
+	StyleGame          Style = "game" // in-game sprite from the pokemon's debut generation
+)
+
+// generationGames lists PokeAPI version keys per generation, preferring the original release
+var generationGames = map[int][]string{
+	1: {"red-blue", "yellow"},
+	2: {"gold", "silver", "crystal"},
+	3: {"ruby-sapphire", "emerald", "firered-leafgreen"},
+	4: {"diamond-pearl", "platinum", "heartgold-soulsilver"},
+	5: {"black-white"},
+	6: {"x-y", "omegaruby-alphasapphire"},
+	7: {"ultra-sun-ultra-moon"},
+	8: {"brilliant-diamond-shining-pearl"},
+	9: {"scarlet-violet"},
+}
+
+var romanGenerations = []string{"", "i", "ii", "iii", "iv", "v", "vi", "vii", "viii", "ix"}
+
+var ErrUnknownStyle = errors.New("unknown artwork style")
+
+// ParseStyle validates a style name; empty means official artwork
+func ParseStyle(s string) (Style, error) {
+	st := Style(strings.ToLower(s))
+	if st == "" {
+		return StyleOfficial, nil
+	}
+	switch st {
+	case StyleOfficial, StyleOfficialShiny, StyleHome, StyleHomeShiny, StylePixel, StylePixelShiny, StyleGame:
+		return st, nil
+	}
+	return "", ErrUnknownStyle
+}
+
+// Fallbacks lists s followed by the styles tried when its sprite is missing, ending with official artwork
+func (s Style) Fallbacks() []Style {
+	switch s {
+	case StyleOfficialShiny:
+		return []Style{s, StyleOfficial}
+	case StyleHome:
+		return []Style{s, StyleOfficial}
+	case StyleHomeShiny:
+		return []Style{s, StyleHome, StyleOfficialShiny, StyleOfficial}
+	case StylePixel:
+		return []Style{s, StyleOfficial}
+	case StylePixelShiny:
+		return []Style{s, StylePixel, StyleOfficial}
+	case StyleGame:
+		return []Style{s, StylePixel, StyleOfficial}
+	}
+	return []Style{StyleOfficial}
+}
+
+// PixelArt reports whether s is low-resolution pixel art that should be scaled with nearest-neighbour
+func (s Style) PixelArt() bool {
+	return s == StylePixel || s == StylePixelShiny || s == StyleGame
+}
+
+// GameSprite returns the in-game sprite URL from generation gen (1-9), preferring transparent variants.
+// Forms (regional, mega) debut after their species, so when gen has no sprite the next generations are tried.
+func (sp Sprites) GameSprite(gen int) string {
+	if gen < 1 {
+		return ""
+	}
+	for ; gen < len(romanGenerations); gen++ {
+		games := sp.Versions["generation-"+romanGenerations[gen]]
+		for _, g := range generationGames[gen] {
+			v := games[g]
+			if v.FrontTransparent != "" {
+				return v.FrontTransparent
+			}
+			if v.FrontDefault != "" {
+				return v.FrontDefault
+			}
+		}
+	}
+	return ""
+}
+
+// URL returns the sprite URL for style, or "" when PokeAPI has none.
+// gen is the debut generation of the species, used by StyleGame.
+func (sp Sprites) URL(style Style, gen int) string {
+	switch style {
+	case StyleGame:
+		return sp.GameSprite(gen)
+	case StyleOfficialShiny:
+		return sp.Other.OfficialArtwork.FrontShiny
+	case StyleHome:
+		return sp.Other.Home.FrontDefault
+	case StyleHomeShiny:
+		return sp.Other.Home.FrontShiny
+	case StylePixel:
+		return sp.FrontDefault
+	case StylePixelShiny:
+		return sp.FrontShiny
+	}
+	return sp.Other.OfficialArtwork.FrontDefault
+}
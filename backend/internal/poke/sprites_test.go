@@ -0,0 +1,67 @@
+package poke
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// alolanRaichu is the sprite/species subset of PokeAPI's pokemon/10100: a gen-1 species whose form debuted in gen 7
+const alolanRaichu = `{
+	"id": 10100,
+	"name": "raichu-alola",
+	"species": {"name": "raichu", "url": "https://pokeapi.co/api/v2/pokemon-species/26/"},
+	"sprites": {
+		"front_default": "pixel.png",
+		"versions": {
+			"generation-i": {"red-blue": {}, "yellow": {}},
+			"generation-vii": {"ultra-sun-ultra-moon": {"front_default": "usum.png"}}
+		}
+	}
+}`
+
+func TestGameSpriteOfForm(t *testing.T) {
+	var p Pokemon
+	if err := json.Unmarshal([]byte(alolanRaichu), &p); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.SpeciesID(); got != 26 {
+		t.Fatalf("SpeciesID = %d, want 26", got)
+	}
+	if got := GenerationOf(p.SpeciesID()); got != 1 {
+		t.Fatalf("GenerationOf(species) = %d, want 1", got)
+	}
+	if got := p.Sprites.URL(StyleGame, GenerationOf(p.SpeciesID())); got != "usum.png" {
+		t.Errorf("game sprite = %q, want the form's debut sprite usum.png", got)
+	}
+}
+
+func TestGameSpritePrefersDebutGeneration(t *testing.T) {
+	var sp Sprites
+	sp.Versions = map[string]map[string]SpritePair{
+		"generation-i":   {"red-blue": {FrontDefault: "rb.png", FrontTransparent: "rb-t.png"}},
+		"generation-iii": {"emerald": {FrontDefault: "e.png"}},
+	}
+	if got := sp.GameSprite(1); got != "rb-t.png" {
+		t.Errorf("GameSprite(1) = %q, want rb-t.png", got)
+	}
+	if got := sp.GameSprite(2); got != "e.png" {
+		t.Errorf("GameSprite(2) = %q, want the next generation's e.png", got)
+	}
+	if got := sp.GameSprite(0); got != "" {
+		t.Errorf("GameSprite(0) = %q, want empty", got)
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	for _, s := range []string{"", "official", "Home-Shiny", "pixel", "game"} {
+		if _, err := ParseStyle(s); err != nil {
+			t.Errorf("ParseStyle(%q): %v", s, err)
+		}
+	}
+	// dream world sprites are SVG only and cannot be rasterized
+	for _, s := range []string{"dream-world", "svg", "official "} {
+		if _, err := ParseStyle(s); err != ErrUnknownStyle {
+			t.Errorf("ParseStyle(%q) = %v, want ErrUnknownStyle", s, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package poke
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+)
+
+// traceEpsilon is the Ramer-Douglas-Peucker tolerance in pixels
+const traceEpsilon = 0.75
+
+// TraceSilhouette traces the alpha mask of src into a simplified SVG path.
+// size sets the rendered width/height (longest edge); 0 keeps the source size.
+// o.AntiAlias has no effect since the path is always solid.
+func TraceSilhouette(src image.Image, size int, o SilhouetteOptions) []byte {
+	b := src.Bounds()
+	mask := Silhouette(src, o)
+	w, h := b.Dx(), b.Dy()
+
+	outW, outH := w, h
+	if size > 0 {
+		r := fitRect(b, size)
+		outW, outH = r.Dx(), r.Dy()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`, w, h, outW, outH)
+	buf.WriteString(`<path fill="#000" fill-rule="evenodd" d="`)
+	for i, loop := range traceContours(mask) {
+		loop = simplifyLoop(loop, traceEpsilon*2) // contour points are in half-pixel units
+		if len(loop) < 3 {
+			continue
+		}
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		for j, p := range loop {
+			if j == 0 {
+				buf.WriteByte('M')
+			} else {
+				buf.WriteByte('L')
+			}
+			buf.WriteString(halfUnit(p.X))
+			buf.WriteByte(' ')
+			buf.WriteString(halfUnit(p.Y))
+		}
+		buf.WriteByte('Z')
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
+
+// halfUnit formats a half-pixel contour coordinate as pixels (samples sit on pixel centres)
+func halfUnit(v int) string {
+	return strconv.FormatFloat(float64(v+1)/2, 'f', -1, 64)
+}
+
+// traceContours runs marching squares over the opaque pixels of mask and returns closed loops.
+// Points are in half-pixel units so edge midpoints between pixel centres stay integral.
+func traceContours(mask *image.NRGBA) [][]image.Point {
+	b := mask.Rect
+	w, h := b.Dx(), b.Dy()
+	in := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return false
+		}
+		return mask.Pix[y*mask.Stride+x*4+3] != 0
+	}
+
+	next := make(map[image.Point]image.Point)
+	for y := -1; y < h; y++ {
+		for x := -1; x < w; x++ {
+			// corners in clockwise order: TL, TR, BR, BL
+			c := [4]bool{in(x, y), in(x+1, y), in(x+1, y+1), in(x, y+1)}
+			if c[0] == c[1] && c[1] == c[2] && c[2] == c[3] {
+				continue
+			}
+			// edge midpoints in the same clockwise order: top, right, bottom, left
+			mid := [4]image.Point{
+				{2*x + 1, 2 * y}, {2*x + 2, 2*y + 1}, {2*x + 1, 2*y + 2}, {2 * x, 2*y + 1},
+			}
+			// an edge going outside->inside is an entry, inside->outside an exit;
+			// each entry links to the next exit clockwise, which keeps orientation consistent across cells
+			for e := 0; e < 4; e++ {
+				if c[e] || !c[(e+1)%4] {
+					continue
+				}
+				for k := 1; k < 4; k++ {
+					j := (e + k) % 4
+					if c[j] && !c[(j+1)%4] {
+						next[mid[e]] = mid[j]
+						break
+					}
+				}
+			}
+		}
+	}
+
+	loops := make([][]image.Point, 0)
+	for len(next) > 0 {
+		var start image.Point
+		for p := range next {
+			start = p
+			break
+		}
+		loop := []image.Point{start}
+		for p := next[start]; p != start; {
+			loop = append(loop, p)
+			q, ok := next[p]
+			delete(next, p)
+			if !ok {
+				break
+			}
+			p = q
+		}
+		delete(next, start)
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// simplifyLoop applies Ramer-Douglas-Peucker to a closed loop, split at its two farthest-apart points
+func simplifyLoop(loop []image.Point, eps float64) []image.Point {
+	if len(loop) < 4 {
+		return loop
+	}
+	far, best := 0, -1.0
+	for i, p := range loop {
+		if d := dist2(loop[0], p); d > best {
+			far, best = i, d
+		}
+	}
+	first := rdp(loop[:far+1], eps)
+	second := rdp(append(append([]image.Point{}, loop[far:]...), loop[0]), eps)
+	out := make([]image.Point, 0, len(first)+len(second)-2)
+	out = append(out, first[:len(first)-1]...)
+	return append(out, second[:len(second)-1]...)
+}
+
+func rdp(pts []image.Point, eps float64) []image.Point {
+	if len(pts) < 3 {
+		return pts
+	}
+	a, b := pts[0], pts[len(pts)-1]
+	idx, dmax := 0, 0.0
+	for i := 1; i < len(pts)-1; i++ {
+		if d := segmentDist(pts[i], a, b); d > dmax {
+			idx, dmax = i, d
+		}
+	}
+	if dmax <= eps {
+		return []image.Point{a, b}
+	}
+	left := rdp(pts[:idx+1], eps)
+	right := rdp(pts[idx:], eps)
+	return append(left[:len(left)-1:len(left)-1], right...)
+}
+
+func dist2(a, b image.Point) float64 {
+	dx, dy := float64(a.X-b.X), float64(a.Y-b.Y)
+	return dx*dx + dy*dy
+}
+
+// segmentDist is the distance from p to segment ab
+func segmentDist(p, a, b image.Point) float64 {
+	l2 := dist2(a, b)
+	if l2 == 0 {
+		return math.Sqrt(dist2(p, a))
+	}
+	t := (float64(p.X-a.X)*float64(b.X-a.X) + float64(p.Y-a.Y)*float64(b.Y-a.Y)) / l2
+	t = math.Max(0, math.Min(1, t))
+	px := float64(a.X) + t*float64(b.X-a.X)
+	py := float64(a.Y) + t*float64(b.Y-a.Y)
+	dx, dy := float64(p.X)-px, float64(p.Y)-py
+	return math.Sqrt(dx*dx + dy*dy)
+}
@@ -0,0 +1,182 @@
+package poke
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// maskOf builds a w x h mask whose pixel (x, y) is opaque when in(x, y)
+func maskOf(w, h int, in func(x, y int) bool) *image.NRGBA {
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if in(x, y) {
+				m.Pix[y*m.Stride+x*4+3] = 255
+			}
+		}
+	}
+	return m
+}
+
+// loopArea is the signed shoelace area of a contour loop in square pixels (points are in half-pixel units)
+func loopArea(loop []image.Point) float64 {
+	a := 0
+	for i, p := range loop {
+		q := loop[(i+1)%len(loop)]
+		a += p.X*q.Y - q.X*p.Y
+	}
+	return float64(a) / 8
+}
+
+// tracedArea is the filled area of contours under the even-odd rule, assuming holes run opposite to outlines
+func tracedArea(loops [][]image.Point) float64 {
+	a := 0.0
+	for _, l := range loops {
+		a += loopArea(l)
+	}
+	return math.Abs(a)
+}
+
+// cornerCut is how far marching squares strays from the pixel count of m: the contour runs through the midpoints
+// between pixel centres, so every convex pixel corner loses 1/8 pixel and every concave one gains 1/8
+func cornerCut(m *image.NRGBA) float64 {
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	in := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && m.Pix[y*m.Stride+x*4+3] != 0
+	}
+	cut := 0.0
+	for y := -1; y < h; y++ {
+		for x := -1; x < w; x++ {
+			n := 0
+			for _, c := range []bool{in(x, y), in(x+1, y), in(x+1, y+1), in(x, y+1)} {
+				if c {
+					n++
+				}
+			}
+			switch {
+			case n == 1:
+				cut -= 0.125
+			case n == 3:
+				cut += 0.125
+			case n == 2 && in(x, y) == in(x+1, y+1):
+				cut -= 0.25 // saddle: the diagonal pixels stay apart, each losing a corner
+			}
+		}
+	}
+	return cut
+}
+
+func pixelCount(m *image.NRGBA) int {
+	n := 0
+	for i := 3; i < len(m.Pix); i += 4 {
+		if m.Pix[i] != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func TestTraceContoursAreas(t *testing.T) {
+	disc := func(r float64) func(x, y int) bool {
+		return func(x, y int) bool { return math.Hypot(float64(x)-20, float64(y)-20) <= r }
+	}
+	for _, tc := range []struct {
+		name  string
+		mask  *image.NRGBA
+		loops int
+	}{
+		{"pixel", maskOf(3, 3, func(x, y int) bool { return x == 1 && y == 1 }), 1},
+		{"square", maskOf(12, 12, func(x, y int) bool { return x >= 2 && x < 10 && y >= 2 && y < 10 }), 1},
+		{"square at the border", maskOf(6, 6, func(x, y int) bool { return true }), 1},
+		{"disc", maskOf(41, 41, disc(15)), 1},
+		{"ring", maskOf(41, 41, func(x, y int) bool { return disc(15)(x, y) && !disc(7)(x, y) }), 2},
+		{"square ring", maskOf(10, 10, func(x, y int) bool {
+			return x >= 1 && x < 9 && y >= 1 && y < 9 && !(x >= 4 && x < 6 && y >= 4 && y < 6)
+		}), 2},
+		{"diagonal saddle", maskOf(4, 4, func(x, y int) bool { return (x == 1 && y == 1) || (x == 2 && y == 2) }), 2},
+		{"checkerboard", maskOf(4, 4, func(x, y int) bool { return (x+y)%2 == 0 }), 8},
+	} {
+		loops := traceContours(tc.mask)
+		if len(loops) != tc.loops {
+			t.Errorf("%s: %d loops, want %d", tc.name, len(loops), tc.loops)
+		}
+		count := pixelCount(tc.mask)
+		want := float64(count) + cornerCut(tc.mask)
+		if got := tracedArea(loops); math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: traced area %v, want %v (%d pixels)", tc.name, got, want, count)
+		}
+		if got := tracedArea(loops); math.Abs(got-float64(count)) > 0.02*float64(count)+1 && count > 100 {
+			t.Errorf("%s: traced area %v strays from %d pixels", tc.name, got, count)
+		}
+	}
+}
+
+func TestTraceContoursOrientation(t *testing.T) {
+	// a hole runs opposite to its outline, so even-odd and nonzero filling agree
+	ring := maskOf(10, 10, func(x, y int) bool {
+		return x >= 1 && x < 9 && y >= 1 && y < 9 && !(x >= 3 && x < 7 && y >= 3 && y < 7)
+	})
+	loops := traceContours(ring)
+	if len(loops) != 2 {
+		t.Fatalf("%d loops, want 2", len(loops))
+	}
+	if a, b := loopArea(loops[0]), loopArea(loops[1]); a*b >= 0 {
+		t.Fatalf("loop areas %v and %v, want opposite signs", a, b)
+	}
+	// separate blobs share the outline orientation
+	saddle := traceContours(maskOf(4, 4, func(x, y int) bool { return (x == 1 && y == 1) || (x == 2 && y == 2) }))
+	if a, b := loopArea(saddle[0]), loopArea(saddle[1]); a*b <= 0 {
+		t.Fatalf("saddle loop areas %v and %v, want the same sign", a, b)
+	}
+	if got := traceContours(maskOf(5, 5, func(x, y int) bool { return false })); len(got) != 0 {
+		t.Fatalf("empty mask traced %d loops", len(got))
+	}
+}
+
+// checkSimplified asserts the Ramer-Douglas-Peucker guarantee: every point of loop lies within eps of the
+// simplified outline, so the enclosed area moves by at most perimeter * eps
+func checkSimplified(t *testing.T, name string, loop, simple []image.Point, eps float64) {
+	t.Helper()
+	if len(simple) < 3 || len(simple) >= len(loop) {
+		t.Errorf("%s: simplified from %d to %d points", name, len(loop), len(simple))
+		return
+	}
+	perimeter := 0.0
+	for i, p := range loop {
+		perimeter += math.Sqrt(dist2(p, loop[(i+1)%len(loop)]))
+		best := math.Inf(1)
+		for j, a := range simple {
+			best = min(best, segmentDist(p, a, simple[(j+1)%len(simple)]))
+		}
+		if best > eps+1e-9 {
+			t.Errorf("%s: point %v is %v half-pixels from the simplified outline", name, p, best)
+		}
+	}
+	// half-pixel units: perimeter/2 pixels, eps/2 pixels
+	if got, want := loopArea(simple), loopArea(loop); math.Abs(got-want) > perimeter/2*eps/2 || got*want <= 0 {
+		t.Errorf("%s: simplified area %v, traced %v", name, got, want)
+	}
+}
+
+func TestSimplifyLoop(t *testing.T) {
+	eps := traceEpsilon * 2
+	square := traceContours(maskOf(12, 12, func(x, y int) bool { return x >= 2 && x < 10 && y >= 2 && y < 10 }))[0]
+	simple := simplifyLoop(square, eps)
+	checkSimplified(t, "square", square, simple, eps)
+	if len(simple) > 8 {
+		t.Errorf("square kept %d points, want at most 8", len(simple))
+	}
+
+	disc := traceContours(maskOf(41, 41, func(x, y int) bool { return math.Hypot(float64(x)-20, float64(y)-20) <= 15 }))[0]
+	simple = simplifyLoop(disc, eps)
+	checkSimplified(t, "disc", disc, simple, eps)
+	if got, want := math.Abs(loopArea(simple)), math.Abs(loopArea(disc)); math.Abs(got-want) > 0.02*want {
+		t.Errorf("simplified disc area %v, want within 2%% of %v", got, want)
+	}
+
+	tiny := []image.Point{{0, 0}, {2, 0}, {1, 2}}
+	if got := simplifyLoop(tiny, eps); len(got) != 3 {
+		t.Errorf("triangle simplified to %v", got)
+	}
+}
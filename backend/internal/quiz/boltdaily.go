@@ -0,0 +1,79 @@
+package quiz
+
+import (
+	"encoding/json"
+	"log"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// dailyBucket holds one nested bucket per date, keyed by player
+var dailyBucket = []byte("daily")
+
+func (s *BoltStore) Claim(date, player string) error {
+	data, err := json.Marshal(DailyEntry{})
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		day, err := tx.Bucket(dailyBucket).CreateBucketIfNotExists([]byte(date))
+		if err != nil {
+			return err
+		}
+		if day.Get([]byte(player)) != nil {
+			return ErrDailyPlayed
+		}
+		return day.Put([]byte(player), data)
+	})
+}
+
+func (s *BoltStore) Finish(date, player string, solved bool, guesses int) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		day := tx.Bucket(dailyBucket).Bucket([]byte(date))
+		if day == nil {
+			return nil
+		}
+		v := day.Get([]byte(player))
+		if v == nil {
+			return nil
+		}
+		var e DailyEntry
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+		if e.Finished {
+			return nil
+		}
+		e.Finished, e.Solved, e.Guesses = true, solved, guesses
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		return day.Put([]byte(player), data)
+	})
+	if err != nil {
+		log.Printf("bolt daily finish %s: %v", date, err)
+	}
+}
+
+func (s *BoltStore) Stats(date string) DailyStats {
+	entries := []DailyEntry{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		day := tx.Bucket(dailyBucket).Bucket([]byte(date))
+		if day == nil {
+			return nil
+		}
+		return day.ForEach(func(_, v []byte) error {
+			var e DailyEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	if err != nil {
+		log.Printf("bolt daily stats %s: %v", date, err)
+	}
+	return dailyStats(date, entries)
+}
@@ -0,0 +1,96 @@
+package quiz
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// difficultyBucket holds PokemonStats keyed by pokemon id
+var difficultyBucket = []byte("difficulty")
+
+func (s *BoltStore) Observe(r Record) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(difficultyBucket)
+		key := []byte(strconv.Itoa(r.PokemonID))
+		var st PokemonStats
+		if v := b.Get(key); v != nil {
+			if err := json.Unmarshal(v, &st); err != nil {
+				return err
+			}
+		}
+		st.Observe(r)
+		data, err := json.Marshal(st)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, data)
+	})
+	if err != nil {
+		log.Printf("bolt difficulty observe %d: %v", r.PokemonID, err)
+	}
+}
+
+func (s *BoltStore) PokemonStats(id int) (PokemonStats, bool) {
+	var st PokemonStats
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(difficultyBucket).Get([]byte(strconv.Itoa(id)))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &st)
+	})
+	if err != nil {
+		log.Printf("bolt difficulty get %d: %v", id, err)
+		return PokemonStats{}, false
+	}
+	return st, found
+}
+
+func (s *BoltStore) AllPokemonStats() []PokemonStats {
+	out := []PokemonStats{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(difficultyBucket).ForEach(func(_, v []byte) error {
+			var st PokemonStats
+			if err := json.Unmarshal(v, &st); err != nil {
+				return err
+			}
+			out = append(out, st)
+			return nil
+		})
+	})
+	if err != nil {
+		log.Printf("bolt difficulty list: %v", err)
+	}
+	SortByDifficulty(out)
+	return out
+}
+
+func (s *BoltStore) ReplacePokemonStats(stats []PokemonStats) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(difficultyBucket); err != nil {
+			return err
+		}
+		b, err := tx.CreateBucket(difficultyBucket)
+		if err != nil {
+			return err
+		}
+		for _, st := range stats {
+			data, err := json.Marshal(st)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(strconv.Itoa(st.PokemonID)), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("bolt difficulty replace: %v", err)
+	}
+}
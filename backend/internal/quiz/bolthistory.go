@@ -0,0 +1,139 @@
+package quiz
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"log"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// historyBucket holds records keyed by insertion sequence; historyKeysBucket marks stored record keys;
+// historyPlayersBucket holds one nested bucket per player listing the sequences of their records
+var (
+	historyBucket        = []byte("history")
+	historyKeysBucket    = []byte("history-keys")
+	historyPlayersBucket = []byte("history-players")
+)
+
+func (s *BoltStore) Add(r Record) bool {
+	// finished sessions are reported on every status read; skip the write transaction when already stored
+	known := false
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		known = tx.Bucket(historyKeysBucket).Get([]byte(r.Key)) != nil
+		return nil
+	})
+	if known {
+		return false
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		log.Printf("bolt history encode %s: %v", r.Key, err)
+		return false
+	}
+	added := false
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		keys := tx.Bucket(historyKeysBucket)
+		if keys.Get([]byte(r.Key)) != nil {
+			return nil
+		}
+		b := tx.Bucket(historyBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], seq)
+		if err := b.Put(k[:], data); err != nil {
+			return err
+		}
+		if err := indexPlayer(tx, r.PlayerID, k[:]); err != nil {
+			return err
+		}
+		added = true
+		return keys.Put([]byte(r.Key), k[:])
+	})
+	if err != nil {
+		log.Printf("bolt history add %s: %v", r.Key, err)
+		return false
+	}
+	return added
+}
+
+// Player reads only the player's records through historyPlayersBucket
+func (s *BoltStore) Player(player string) []Record {
+	out := []Record{}
+	if player == "" {
+		return out
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seqs := tx.Bucket(historyPlayersBucket).Bucket([]byte(player))
+		if seqs == nil {
+			return nil
+		}
+		records := tx.Bucket(historyBucket)
+		return seqs.ForEach(func(k, _ []byte) error {
+			var r Record
+			if err := json.Unmarshal(records.Get(k), &r); err != nil {
+				return err
+			}
+			out = append(out, r)
+			return nil
+		})
+	})
+	if err != nil {
+		log.Printf("bolt history read %s: %v", player, err)
+	}
+	return out
+}
+
+// indexPlayer lists the record at seq under its player (records without a player are not indexed)
+func indexPlayer(tx *bolt.Tx, player string, seq []byte) error {
+	if player == "" {
+		return nil
+	}
+	b, err := tx.Bucket(historyPlayersBucket).CreateBucketIfNotExists([]byte(player))
+	if err != nil {
+		return err
+	}
+	return b.Put(seq, nil)
+}
+
+// indexHistoryPlayers builds historyPlayersBucket for a file written before it existed
+func indexHistoryPlayers(tx *bolt.Tx) error {
+	if tx.Bucket(historyPlayersBucket) != nil {
+		return nil
+	}
+	if _, err := tx.CreateBucket(historyPlayersBucket); err != nil {
+		return err
+	}
+	return tx.Bucket(historyBucket).ForEach(func(k, v []byte) error {
+		var r struct {
+			PlayerID string `json:"playerId"`
+		}
+		if err := json.Unmarshal(v, &r); err != nil {
+			return err
+		}
+		return indexPlayer(tx, r.PlayerID, k)
+	})
+}
+
+func (s *BoltStore) Each(fn func(Record) bool) {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if !fn(r) {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("bolt history read: %v", err)
+	}
+}
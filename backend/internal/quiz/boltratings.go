@@ -0,0 +1,102 @@
+package quiz
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ratingsBucket holds Ratings keyed "player:<id>" and "pokemon:<id>"
+var ratingsBucket = []byte("ratings")
+
+const (
+	playerRatingPrefix  = "player:"
+	pokemonRatingPrefix = "pokemon:"
+)
+
+func (s *BoltStore) RateSession(r Record) {
+	if r.PlayerID == "" {
+		return
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ratingsBucket)
+		pk, mk := []byte(playerRatingPrefix+r.PlayerID), []byte(pokemonRatingPrefix+strconv.Itoa(r.PokemonID))
+		var player, pokemon Rating
+		if err := getRating(b, pk, &player); err != nil {
+			return err
+		}
+		if err := getRating(b, mk, &pokemon); err != nil {
+			return err
+		}
+		player, pokemon = rateGame(player, pokemon, r)
+		if err := putRating(b, pk, player); err != nil {
+			return err
+		}
+		return putRating(b, mk, pokemon)
+	})
+	if err != nil {
+		log.Printf("bolt rate session %s: %v", r.Key, err)
+	}
+}
+
+func (s *BoltStore) PlayerRating(player string) Rating {
+	return s.rating(playerRatingPrefix + player)
+}
+
+func (s *BoltStore) PokemonRating(id int) Rating {
+	return s.rating(pokemonRatingPrefix + strconv.Itoa(id))
+}
+
+func (s *BoltStore) PokemonRatings() map[int]Rating {
+	out := map[int]Rating{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(ratingsBucket).Cursor()
+		prefix := []byte(pokemonRatingPrefix)
+		for k, v := c.Seek(prefix); k != nil && strings.HasPrefix(string(k), pokemonRatingPrefix); k, v = c.Next() {
+			id, err := strconv.Atoi(string(k[len(prefix):]))
+			if err != nil {
+				continue
+			}
+			var r Rating
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			out[id] = r
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("bolt ratings list: %v", err)
+	}
+	return out
+}
+
+func (s *BoltStore) rating(key string) Rating {
+	var r Rating
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getRating(tx.Bucket(ratingsBucket), []byte(key), &r)
+	})
+	if err != nil {
+		log.Printf("bolt rating %s: %v", key, err)
+		return Rating{}.orDefault()
+	}
+	return r.orDefault()
+}
+
+func getRating(b *bolt.Bucket, key []byte, r *Rating) error {
+	if v := b.Get(key); v != nil {
+		return json.Unmarshal(v, r)
+	}
+	return nil
+}
+
+func putRating(b *bolt.Bucket, key []byte, r Rating) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, data)
+}
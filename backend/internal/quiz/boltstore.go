@@ -0,0 +1,217 @@
+package quiz
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var sessionsBucket = []byte("sessions")
+
+// BoltStore is a SessionStore persisted in a bbolt file so sessions survive restarts.
+// Sessions expire ttl after their last write; Update runs inside a bbolt write transaction.
+// It is also a DailyBook, History and DifficultyStore, keeping daily attempts, finished sessions and pokemon stats
+// in the same file (they do not expire).
+type BoltStore struct {
+	db  *bolt.DB
+	ttl time.Duration
+
+	timeUp atomic.Pointer[func(*Session)]
+	stop   chan struct{}
+	once   sync.Once
+}
+
+type boltRecord struct {
+	Session *Session  `json:"session"`
+	Touched time.Time `json:"touched"`
+}
+
+// OpenBoltStore opens (or creates) the database file at path; ttl <= 0 disables expiry
+func OpenBoltStore(path string, ttl time.Duration) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{sessionsBucket, dailyBucket, historyBucket, historyKeysBucket, difficultyBucket, ratingsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return indexHistoryPlayers(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db, ttl: ttl, stop: make(chan struct{})}, nil
+}
+
+func (s *BoltStore) Get(id string) (*Session, bool) {
+	var rec boltRecord
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(sessionsBucket).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &rec)
+	})
+	if err != nil {
+		log.Printf("bolt store get %s: %v", id, err)
+		return nil, false
+	}
+	if !found || rec.Session == nil || s.expired(rec, time.Now()) {
+		return nil, false
+	}
+	return rec.Session, true
+}
+
+func (s *BoltStore) Set(sess *Session) {
+	data, err := json.Marshal(boltRecord{Session: sess, Touched: time.Now()})
+	if err != nil {
+		log.Printf("bolt store encode %s: %v", sess.ID, err)
+		return
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(sess.ID), data)
+	})
+	if err != nil {
+		log.Printf("bolt store set %s: %v", sess.ID, err)
+	}
+}
+
+func (s *BoltStore) Update(id string, fn func(*Session) error) (*Session, error) {
+	var out *Session
+	var fnErr error
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		v := b.Get([]byte(id))
+		if v == nil {
+			return ErrSessionNotFound
+		}
+		var rec boltRecord
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return err
+		}
+		if rec.Session == nil || s.expired(rec, time.Now()) {
+			return ErrSessionNotFound
+		}
+		out = rec.Session
+		if fnErr = fn(out); fnErr != nil {
+			return nil // nothing written
+		}
+		data, err := json.Marshal(boltRecord{Session: out, Touched: time.Now()})
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(id), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, fnErr
+}
+
+func (s *BoltStore) Delete(id string) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		log.Printf("bolt store delete %s: %v", id, err)
+	}
+}
+
+// StartJanitor removes expired sessions and settles lapsed time limits every interval until Close is called
+func (s *BoltStore) StartJanitor(interval time.Duration) {
+	if s.ttl <= 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				if err := s.collect(time.Now()); err != nil {
+					log.Printf("bolt store collect: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the janitor and closes the database
+func (s *BoltStore) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return s.db.Close()
+}
+
+// OnTimeUp sets the function the janitor calls for each session it timed out
+func (s *BoltStore) OnTimeUp(fn func(*Session)) {
+	s.timeUp.Store(&fn)
+}
+
+// collect deletes expired sessions and times out the live ones whose deadline passed, handing those to the
+// OnTimeUp function once stored
+func (s *BoltStore) collect(now time.Time) error {
+	var lapsed []*Session
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		var stale [][]byte
+		settled := map[string][]byte{}
+		err := b.ForEach(func(k, v []byte) error {
+			var rec boltRecord
+			if err := json.Unmarshal(v, &rec); err != nil || rec.Session == nil || s.expired(rec, now) {
+				stale = append(stale, append([]byte(nil), k...))
+				return nil
+			}
+			if rec.Session.TimeLimit <= 0 || !rec.Session.settleDeadline() {
+				return nil
+			}
+			// keep Touched: settling is not an access
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			settled[string(k)] = data
+			lapsed = append(lapsed, rec.Session)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		for k, data := range settled {
+			if err := b.Put([]byte(k), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if fn := s.timeUp.Load(); fn != nil {
+		for _, sess := range lapsed {
+			(*fn)(sess)
+		}
+	}
+	return nil
+}
+
+func (s *BoltStore) expired(rec boltRecord, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(rec.Touched) > s.ttl
+}
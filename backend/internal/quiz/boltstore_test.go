@@ -0,0 +1,111 @@
+package quiz
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// age moves the last write of a stored session d into the past
+func (s *BoltStore) age(t *testing.T, id string, d time.Duration) {
+	t.Helper()
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		var rec boltRecord
+		if err := json.Unmarshal(b.Get([]byte(id)), &rec); err != nil {
+			return err
+		}
+		rec.Touched = rec.Touched.Add(-d)
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(id), data)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBoltStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	s := openTestBolt(t, path)
+	sess := testSession(CooldownPractice)
+	sess.Regions = []string{"kanto", "johto"}
+	sess.AcceptAnswers = []string{"pikachu", "ピカチュウ"}
+	sess.TimeLimit = time.Hour
+	sess.Mode, sess.PlayerID, sess.Streak, sess.Used = ModeEndless, "p1", 2, []int{1, 4}
+	s.Set(sess)
+	want, err := s.Update(sess.ID, func(s *Session) error {
+		_, err := s.SubmitGuess("raichu")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openTestBolt(t, path)
+	defer s.Close()
+	got, ok := s.Get(sess.ID)
+	if !ok {
+		t.Fatal("session lost on reopen")
+	}
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if string(gotJSON) != string(wantJSON) {
+		t.Fatalf("reopened session differs:\n got %s\nwant %s", gotJSON, wantJSON)
+	}
+	if got.Guesses != 1 || len(got.Events) == 0 {
+		t.Fatalf("update not persisted: %d guesses, %d events", got.Guesses, len(got.Events))
+	}
+}
+
+func TestBoltStoreExpiresAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	s, err := OpenBoltStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, live := testSession(Cooldown{}), testSession(Cooldown{})
+	s.Set(stale)
+	s.Set(live)
+	s.age(t, stale.ID, 2*time.Hour)
+	s.age(t, live.ID, 30*time.Minute)
+	s.Close()
+
+	// Touched is stored, so a restart neither forgets nor resets the ttl
+	s, err = OpenBoltStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, ok := s.Get(stale.ID); ok {
+		t.Fatal("Get returned a session that expired before the restart")
+	}
+	if _, err := s.Update(stale.ID, func(*Session) error { return nil }); err != ErrSessionNotFound {
+		t.Fatalf("Update of an expired session: %v, want ErrSessionNotFound", err)
+	}
+	if _, ok := s.Get(live.ID); !ok {
+		t.Fatal("live session lost on reopen")
+	}
+
+	if err := s.collect(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	if len(keys) != 1 || keys[0] != live.ID {
+		t.Fatalf("sessions after collect = %v, want only %s", keys, live.ID)
+	}
+}
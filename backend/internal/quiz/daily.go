@@ -0,0 +1,136 @@
+package quiz
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// ModeDaily marks the daily challenge: the same pokemon for every player on a date, one attempt each
+const ModeDaily = "daily"
+
+var ErrDailyPlayed = errors.New("daily challenge already played")
+
+// dailyZone is where the daily challenge rolls over (midnight JST)
+var dailyZone = time.FixedZone("JST", 9*60*60)
+
+// DailyRetention is how many days of daily attempts the memory book keeps
+const DailyRetention = 31
+
+// DailyDate returns the challenge date (YYYY-MM-DD) at t
+func DailyDate(t time.Time) string {
+	return t.In(dailyZone).Format(time.DateOnly)
+}
+
+// DailySeed derives the selection seed of a date, so every server picks the same pokemon
+func DailySeed(date string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte("psq-daily:" + date))
+	return h.Sum64()
+}
+
+// DailyEntry is one player's attempt at a daily challenge
+type DailyEntry struct {
+	Finished bool `json:"finished"`
+	Solved   bool `json:"solved"`
+	Guesses  int  `json:"guesses"`
+}
+
+// DailyStats aggregates the attempts of one date
+type DailyStats struct {
+	Date       string  `json:"date"`
+	Players    int     `json:"players"`    // attempts started
+	Finished   int     `json:"finished"`   // attempts solved or given up
+	Solved     int     `json:"solved"`     // attempts solved
+	SolveRate  float64 `json:"solveRate"`  // solved / finished
+	AvgGuesses float64 `json:"avgGuesses"` // guesses per solved attempt
+}
+
+// DailyBook tracks daily attempts per player
+type DailyBook interface {
+	// Claim registers player's attempt at date's challenge; ErrDailyPlayed if they already have one
+	Claim(date, player string) error
+	// Finish records the outcome of an attempt; only the first call per player and date counts
+	Finish(date, player string, solved bool, guesses int)
+	Stats(date string) DailyStats
+}
+
+// dailyStats aggregates entries of date
+func dailyStats(date string, entries []DailyEntry) DailyStats {
+	st := DailyStats{Date: date, Players: len(entries)}
+	guesses := 0
+	for _, e := range entries {
+		if !e.Finished {
+			continue
+		}
+		st.Finished++
+		if e.Solved {
+			st.Solved++
+			guesses += e.Guesses
+		}
+	}
+	if st.Finished > 0 {
+		st.SolveRate = float64(st.Solved) / float64(st.Finished)
+	}
+	if st.Solved > 0 {
+		st.AvgGuesses = float64(guesses) / float64(st.Solved)
+	}
+	return st
+}
+
+// MemoryDailyBook is an in-process DailyBook keeping the last DailyRetention days
+type MemoryDailyBook struct {
+	mu   sync.Mutex
+	days map[string]map[string]*DailyEntry // date -> player -> entry
+}
+
+func NewMemoryDailyBook() *MemoryDailyBook {
+	return &MemoryDailyBook{days: make(map[string]map[string]*DailyEntry)}
+}
+
+func (b *MemoryDailyBook) Claim(date, player string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	day, ok := b.days[date]
+	if !ok {
+		b.pruneLocked()
+		day = make(map[string]*DailyEntry)
+		b.days[date] = day
+	}
+	if _, ok := day[player]; ok {
+		return ErrDailyPlayed
+	}
+	day[player] = &DailyEntry{}
+	return nil
+}
+
+func (b *MemoryDailyBook) Finish(date, player string, solved bool, guesses int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.days[date][player]
+	if !ok || e.Finished {
+		return
+	}
+	e.Finished, e.Solved, e.Guesses = true, solved, guesses
+}
+
+func (b *MemoryDailyBook) Stats(date string) DailyStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := make([]DailyEntry, 0, len(b.days[date]))
+	for _, e := range b.days[date] {
+		entries = append(entries, *e)
+	}
+	return dailyStats(date, entries)
+}
+
+// pruneLocked drops days older than DailyRetention; b.mu must be held
+func (b *MemoryDailyBook) pruneLocked() {
+	oldest := DailyDate(time.Now().AddDate(0, 0, -DailyRetention))
+	for d := range b.days {
+		if d < oldest {
+			delete(b.days, d)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package quiz
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDailyBooks(t *testing.T) {
+	books := map[string]DailyBook{
+		"memory": NewMemoryDailyBook(),
+		"bolt":   openTestBolt(t, filepath.Join(t.TempDir(), "quiz.db")),
+	}
+	today := DailyDate(time.Now())
+	yesterday := DailyDate(time.Now().AddDate(0, 0, -1))
+	for name, b := range books {
+		t.Run(name, func(t *testing.T) {
+			if s, ok := b.(*BoltStore); ok {
+				defer s.Close()
+			}
+			for _, p := range []string{"a", "b", "c", "d"} {
+				if err := b.Claim(today, p); err != nil {
+					t.Fatalf("claim %s: %v", p, err)
+				}
+			}
+			if err := b.Claim(today, "a"); err != ErrDailyPlayed {
+				t.Fatalf("second claim: %v, want ErrDailyPlayed", err)
+			}
+			// another date is another challenge
+			if err := b.Claim(yesterday, "a"); err != nil {
+				t.Fatalf("claim of another date: %v", err)
+			}
+
+			b.Finish(today, "a", true, 2)
+			b.Finish(today, "a", false, 9) // only the first finish counts
+			b.Finish(today, "b", true, 5)
+			b.Finish(today, "c", false, 3)
+			b.Finish(today, "nobody", true, 1) // never claimed
+			b.Finish("2000-01-01", "a", true, 1)
+
+			want := DailyStats{Date: today, Players: 4, Finished: 3, Solved: 2, SolveRate: 2.0 / 3, AvgGuesses: 3.5}
+			if got := b.Stats(today); got != want {
+				t.Fatalf("Stats = %+v, want %+v", got, want)
+			}
+			if got := b.Stats(yesterday); got != (DailyStats{Date: yesterday, Players: 1}) {
+				t.Fatalf("Stats of another date = %+v", got)
+			}
+			if got := b.Stats("2000-01-01"); got != (DailyStats{Date: "2000-01-01"}) {
+				t.Fatalf("Stats of an unplayed date = %+v", got)
+			}
+		})
+	}
+}
+
+func TestBoltDailySurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.db")
+	s := openTestBolt(t, path)
+	today := DailyDate(time.Now())
+	if err := s.Claim(today, "a"); err != nil {
+		t.Fatal(err)
+	}
+	s.Finish(today, "a", true, 1)
+	s.Close()
+
+	s = openTestBolt(t, path)
+	defer s.Close()
+	if err := s.Claim(today, "a"); err != ErrDailyPlayed {
+		t.Fatalf("claim after reopen: %v, want ErrDailyPlayed", err)
+	}
+	if got := s.Stats(today); got.Solved != 1 || got.AvgGuesses != 1 {
+		t.Fatalf("Stats after reopen = %+v", got)
+	}
+}
+
+func TestDailyDateRollsOverAtMidnightJST(t *testing.T) {
+	before := time.Date(2026, 3, 1, 14, 59, 0, 0, time.UTC) // 23:59 JST
+	if got := DailyDate(before); got != "2026-03-01" {
+		t.Errorf("DailyDate(%v) = %s", before, got)
+	}
+	if got := DailyDate(before.Add(time.Minute)); got != "2026-03-02" {
+		t.Errorf("DailyDate(%v) = %s", before.Add(time.Minute), got)
+	}
+	if DailySeed("2026-03-01") == DailySeed("2026-03-02") {
+		t.Error("consecutive dates share a seed")
+	}
+}
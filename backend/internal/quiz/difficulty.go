@@ -0,0 +1,177 @@
+package quiz
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Difficulty scale: 0 is trivial, 1 is almost never solved. Pokemon without plays rate DefaultDifficulty.
+const (
+	DefaultDifficulty = 0.5
+	HardDifficulty    = 0.6 // suggested minDifficulty for "only hard ones"
+)
+
+// difficultyPrior is how many average plays a rating starts from, so a few sessions can't swing it to an extreme
+const difficultyPrior = 5
+
+// slowSolve is the solve time that counts as half of the time component
+const slowSolve = 30 * time.Second
+
+// PokemonStats aggregates the finished sessions of one pokemon
+type PokemonStats struct {
+	PokemonID  int           `json:"pokemonId"`
+	Plays      int           `json:"plays"`
+	Solved     int           `json:"solved"`
+	GaveUp     int           `json:"gaveUp"`
+	SolveTime  time.Duration `json:"solveTime"` // total over solved sessions
+	SolveRate  float64       `json:"solveRate"`
+	GiveUpRate float64       `json:"giveUpRate"`
+	AvgSolveMs int64         `json:"avgSolveMs"`
+	Difficulty float64       `json:"difficulty"`
+}
+
+// Observe folds one finished session into the stats
+func (st *PokemonStats) Observe(r Record) {
+	st.PokemonID = r.PokemonID
+	st.Plays++
+	if r.Solved {
+		st.Solved++
+		st.SolveTime += r.Elapsed
+	}
+	if r.GaveUp {
+		st.GaveUp++
+	}
+	st.rate()
+}
+
+// rate derives the rates and difficulty from the counters
+func (st *PokemonStats) rate() {
+	if st.Plays == 0 {
+		st.Difficulty = DefaultDifficulty
+		return
+	}
+	st.SolveRate = float64(st.Solved) / float64(st.Plays)
+	st.GiveUpRate = float64(st.GaveUp) / float64(st.Plays)
+
+	// failing weighs most, then slow solves, then giving up outright
+	raw := 0.6 * (1 - st.SolveRate)
+	raw += 0.15 * st.GiveUpRate
+	if st.Solved > 0 {
+		avg := st.SolveTime / time.Duration(st.Solved)
+		st.AvgSolveMs = avg.Milliseconds()
+		raw += 0.25 * float64(avg) / float64(avg+slowSolve)
+	} else {
+		raw += 0.25
+	}
+	d := (float64(st.Plays)*raw + difficultyPrior*DefaultDifficulty) / float64(st.Plays+difficultyPrior)
+	st.Difficulty = math.Round(d*1000) / 1000
+}
+
+// Aggregate computes the stats of every pokemon in the history h
+func Aggregate(h History) []PokemonStats {
+	byID := map[int]*PokemonStats{}
+	h.Each(func(r Record) bool {
+		st, ok := byID[r.PokemonID]
+		if !ok {
+			st = &PokemonStats{}
+			byID[r.PokemonID] = st
+		}
+		st.Observe(r)
+		return true
+	})
+	out := make([]PokemonStats, 0, len(byID))
+	for _, st := range byID {
+		out = append(out, *st)
+	}
+	SortByDifficulty(out)
+	return out
+}
+
+// SortByDifficulty orders stats hardest first (ties by id)
+func SortByDifficulty(stats []PokemonStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Difficulty != stats[j].Difficulty {
+			return stats[i].Difficulty > stats[j].Difficulty
+		}
+		return stats[i].PokemonID < stats[j].PokemonID
+	})
+}
+
+// Score returns the points of a session: solving a harder pokemon (by s.Difficulty, fixed at start) is worth more,
+// extra guesses and hints cost.
+func Score(s *Session) int {
+	if !s.Solved {
+		return 0
+	}
+	base := 100 * (0.5 + s.Difficulty) // 50 .. 150
+	factor := 1 - 0.1*float64(max(s.Guesses-1, 0)) - 0.2*float64(s.HintsUsed)
+	return int(math.Round(base * max(factor, 0.1)))
+}
+
+// DifficultyStore keeps PokemonStats per pokemon
+type DifficultyStore interface {
+	// Observe folds one finished session (each only once) into its pokemon's stats
+	Observe(r Record)
+	// PokemonStats returns the stats of a pokemon (ok=false if never played)
+	PokemonStats(id int) (PokemonStats, bool)
+	// AllPokemonStats returns every rated pokemon, hardest first
+	AllPokemonStats() []PokemonStats
+	// ReplacePokemonStats swaps all stats, e.g. after recomputing from the history
+	ReplacePokemonStats(stats []PokemonStats)
+}
+
+// DifficultyOf returns the difficulty of id in ds, DefaultDifficulty if unrated
+func DifficultyOf(ds DifficultyStore, id int) float64 {
+	if st, ok := ds.PokemonStats(id); ok {
+		return st.Difficulty
+	}
+	return DefaultDifficulty
+}
+
+// MemoryDifficulty is an in-process DifficultyStore
+type MemoryDifficulty struct {
+	mu    sync.RWMutex
+	stats map[int]PokemonStats
+}
+
+func NewMemoryDifficulty() *MemoryDifficulty {
+	return &MemoryDifficulty{stats: make(map[int]PokemonStats)}
+}
+
+func (m *MemoryDifficulty) Observe(r Record) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.stats[r.PokemonID]
+	st.Observe(r)
+	m.stats[r.PokemonID] = st
+}
+
+func (m *MemoryDifficulty) PokemonStats(id int) (PokemonStats, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	st, ok := m.stats[id]
+	return st, ok
+}
+
+func (m *MemoryDifficulty) AllPokemonStats() []PokemonStats {
+	m.mu.RLock()
+	out := make([]PokemonStats, 0, len(m.stats))
+	for _, st := range m.stats {
+		out = append(out, st)
+	}
+	m.mu.RUnlock()
+	SortByDifficulty(out)
+	return out
+}
+
+func (m *MemoryDifficulty) ReplacePokemonStats(stats []PokemonStats) {
+	byID := make(map[int]PokemonStats, len(stats))
+	for _, st := range stats {
+		byID[st.PokemonID] = st
+	}
+	m.mu.Lock()
+	m.stats = byID
+	m.mu.Unlock()
+}
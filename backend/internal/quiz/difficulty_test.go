@@ -0,0 +1,124 @@
+package quiz
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// observe folds n records of pokemon id with the given outcome into st
+func observe(st *PokemonStats, id, n int, solved bool, elapsed time.Duration) {
+	for i := 0; i < n; i++ {
+		st.Observe(Record{PokemonID: id, Solved: solved, GaveUp: !solved, Elapsed: elapsed})
+	}
+}
+
+func TestPokemonStatsRate(t *testing.T) {
+	var unplayed PokemonStats
+	unplayed.rate()
+	if unplayed.Difficulty != DefaultDifficulty {
+		t.Errorf("unplayed difficulty = %v, want %v", unplayed.Difficulty, DefaultDifficulty)
+	}
+
+	for _, tc := range []struct {
+		name         string
+		solves, quit int
+		solveTime    time.Duration
+		want         float64
+	}{
+		// raw 0.25*30/(30+30) = 0.125, pulled halfway to 0.5 by the 5-play prior
+		{"all solved at 30s", 5, 0, 30 * time.Second, 0.313},
+		// raw 0.6 + 0.15 + 0.25 = 1
+		{"all given up", 0, 5, 0, 0.75},
+		{"many given up", 0, 95, 0, 0.975},
+		// raw 0.6*0.5 + 0.15*0.5 + 0.25*10/40 = 0.4375
+		{"half solved at 10s", 5, 5, 10 * time.Second, 0.458},
+	} {
+		var st PokemonStats
+		observe(&st, 25, tc.solves, true, tc.solveTime)
+		observe(&st, 25, tc.quit, false, 0)
+		if st.Difficulty != tc.want {
+			t.Errorf("%s: difficulty = %v, want %v", tc.name, st.Difficulty, tc.want)
+		}
+		if st.Plays != tc.solves+tc.quit || st.Solved != tc.solves || st.GaveUp != tc.quit {
+			t.Errorf("%s: counters %+v", tc.name, st)
+		}
+		if tc.solves > 0 && st.AvgSolveMs != tc.solveTime.Milliseconds() {
+			t.Errorf("%s: AvgSolveMs = %d, want %d", tc.name, st.AvgSolveMs, tc.solveTime.Milliseconds())
+		}
+	}
+
+	// slower solves rate harder
+	var fast, slow PokemonStats
+	observe(&fast, 1, 10, true, 5*time.Second)
+	observe(&slow, 2, 10, true, time.Minute)
+	if fast.Difficulty >= slow.Difficulty {
+		t.Errorf("fast solves rate %v, slow %v; want fast easier", fast.Difficulty, slow.Difficulty)
+	}
+}
+
+func TestScore(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		solved     bool
+		difficulty float64
+		guesses    int
+		hints      int
+		want       int
+	}{
+		{"unsolved", false, 1, 1, 0, 0},
+		{"average first try", true, 0.5, 1, 0, 100},
+		{"trivial first try", true, 0, 1, 0, 50},
+		{"hardest first try", true, 1, 1, 0, 150},
+		{"extra guesses and a hint", true, 0.5, 3, 1, 60},
+		{"floor", true, 0.5, 30, 5, 10},
+	} {
+		sess := testSession(CooldownPractice)
+		sess.Solved, sess.Difficulty, sess.Guesses, sess.HintsUsed = tc.solved, tc.difficulty, tc.guesses, tc.hints
+		if got := Score(sess); got != tc.want {
+			t.Errorf("%s: Score = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	h := NewMemoryHistory(0)
+	n := 0
+	add := func(id int, solved bool) {
+		n++
+		h.Add(Record{Key: fmt.Sprint(n), PokemonID: id, Solved: solved, GaveUp: !solved, Elapsed: 10 * time.Second})
+	}
+	for i := 0; i < 4; i++ {
+		add(25, true)
+		add(6, false)
+	}
+	add(1, true)
+	add(1, false)
+	add(133, true)
+	add(133, true)
+
+	stats := Aggregate(h)
+	var ids []int
+	for _, st := range stats {
+		ids = append(ids, st.PokemonID)
+	}
+	// 133 and 25 were always solved, but 25's four plays pull it further below the prior
+	if fmt.Sprint(ids) != "[6 1 133 25]" {
+		t.Fatalf("Aggregate order = %v, want [6 1 133 25]", ids)
+	}
+	for _, st := range stats {
+		var want PokemonStats
+		h.Each(func(r Record) bool {
+			if r.PokemonID == st.PokemonID {
+				want.Observe(r)
+			}
+			return true
+		})
+		if st != want {
+			t.Errorf("pokemon %d: %+v, want %+v", st.PokemonID, st, want)
+		}
+	}
+	if got := Aggregate(NewMemoryHistory(0)); len(got) != 0 {
+		t.Errorf("empty history aggregates to %v", got)
+	}
+}
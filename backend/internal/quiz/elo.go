@@ -0,0 +1,113 @@
+package quiz
+
+import (
+	"math"
+	"sync"
+)
+
+// DefaultRating is where new players and pokemon start
+const DefaultRating = 1500
+
+// eloK is how far one session can move a rating
+const eloK = 32
+
+// Rating is an Elo-style rating with the number of sessions behind it
+type Rating struct {
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+}
+
+// orDefault returns r, or a fresh rating if r was never played
+func (r Rating) orDefault() Rating {
+	if r.Games == 0 && r.Rating == 0 {
+		r.Rating = DefaultRating
+	}
+	return r
+}
+
+// Outcome scores a finished session for the player: 0 unless solved, and a solve counts more the faster it was
+// (1 when instant, 0.75 at 30 seconds, approaching 0.5 for very slow solves)
+func Outcome(r Record) float64 {
+	if !r.Solved {
+		return 0
+	}
+	return 0.5 + 0.5*float64(slowSolve)/float64(r.Elapsed+slowSolve)
+}
+
+// Expected is the probability-like score a player rated p is expected to reach against a pokemon rated q
+func Expected(p, q float64) float64 {
+	return 1 / (1 + math.Pow(10, (q-p)/400))
+}
+
+// rateGame updates a player and a pokemon rating with the outcome of a session
+func rateGame(player, pokemon Rating, r Record) (Rating, Rating) {
+	player, pokemon = player.orDefault(), pokemon.orDefault()
+	delta := eloK * (Outcome(r) - Expected(player.Rating, pokemon.Rating))
+	player.Rating += delta
+	pokemon.Rating -= delta
+	player.Games++
+	pokemon.Games++
+	return player, pokemon
+}
+
+// RatingStore keeps Elo-style ratings of players and pokemon
+type RatingStore interface {
+	// RateSession updates the player's and the pokemon's rating after a finished session (each only once);
+	// sessions without a player are not rated
+	RateSession(r Record)
+	PlayerRating(player string) Rating
+	PokemonRating(id int) Rating
+	// PokemonRatings returns the rating of every pokemon rated so far
+	PokemonRatings() map[int]Rating
+}
+
+// MemoryRatings is an in-process RatingStore
+type MemoryRatings struct {
+	mu      sync.RWMutex
+	players map[string]Rating
+	pokemon map[int]Rating
+}
+
+func NewMemoryRatings() *MemoryRatings {
+	return &MemoryRatings{players: make(map[string]Rating), pokemon: make(map[int]Rating)}
+}
+
+func (m *MemoryRatings) RateSession(r Record) {
+	if r.PlayerID == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.players[r.PlayerID], m.pokemon[r.PokemonID] = rateGame(m.players[r.PlayerID], m.pokemon[r.PokemonID], r)
+}
+
+func (m *MemoryRatings) PlayerRating(player string) Rating {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.players[player].orDefault()
+}
+
+func (m *MemoryRatings) PokemonRating(id int) Rating {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pokemon[id].orDefault()
+}
+
+func (m *MemoryRatings) PokemonRatings() map[int]Rating {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[int]Rating, len(m.pokemon))
+	for id, r := range m.pokemon {
+		out[id] = r
+	}
+	return out
+}
+
+// ratingWindow is the rating gap at which an adaptive pick is e^-1 times as likely as an even match
+const ratingWindow = 200
+
+// Closeness weights a pokemon rated q for a player rated p: 1 for an even match, falling off with the gap
+func Closeness(p, q float64) float64 {
+	d := (q - p) / ratingWindow
+	return math.Exp(-d * d)
+}
@@ -0,0 +1,98 @@
+package quiz
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestOutcome(t *testing.T) {
+	for _, tc := range []struct {
+		r    Record
+		want float64
+	}{
+		{Record{Solved: true}, 1},
+		{Record{Solved: true, Elapsed: 30 * time.Second}, 0.75},
+		{Record{Solved: true, Elapsed: 90 * time.Second}, 0.625},
+		{Record{GaveUp: true, Elapsed: time.Second}, 0},
+		{Record{TimedOut: true}, 0},
+		{Record{Missed: true}, 0},
+	} {
+		if got := Outcome(tc.r); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Outcome(%+v) = %v, want %v", tc.r, got, tc.want)
+		}
+	}
+	// solves never score below a failure, however slow
+	if got := Outcome(Record{Solved: true, Elapsed: 24 * time.Hour}); got <= 0.5 {
+		t.Errorf("very slow solve scores %v, want above 0.5", got)
+	}
+}
+
+func TestRateGame(t *testing.T) {
+	even := Rating{}
+	for _, tc := range []struct {
+		name            string
+		player, pokemon Rating
+		r               Record
+		delta           float64 // change of the player's rating; the pokemon moves by the opposite
+	}{
+		// even match: expected 0.5, so K*(1-0.5)
+		{"instant solve", even, even, Record{Solved: true}, 16},
+		{"give up", even, even, Record{GaveUp: true}, -16},
+		{"30s solve", even, even, Record{Solved: true, Elapsed: 30 * time.Second}, 8},
+		// beating a much weaker pokemon earns little, failing against it costs nearly the full K
+		{"strong player solves", Rating{Rating: 1900, Games: 10}, even, Record{Solved: true}, 32 * (1 - Expected(1900, 1500))},
+		{"strong player gives up", Rating{Rating: 1900, Games: 10}, even, Record{GaveUp: true}, -32 * Expected(1900, 1500)},
+	} {
+		player, pokemon := rateGame(tc.player, tc.pokemon, tc.r)
+		p0, q0 := tc.player.orDefault(), tc.pokemon.orDefault()
+		if got := player.Rating - p0.Rating; math.Abs(got-tc.delta) > 1e-9 {
+			t.Errorf("%s: player moved %v, want %v", tc.name, got, tc.delta)
+		}
+		if got := pokemon.Rating - q0.Rating; math.Abs(got+tc.delta) > 1e-9 {
+			t.Errorf("%s: pokemon moved %v, want %v", tc.name, got, -tc.delta)
+		}
+		if player.Games != p0.Games+1 || pokemon.Games != q0.Games+1 {
+			t.Errorf("%s: games %d/%d not counted", tc.name, player.Games, pokemon.Games)
+		}
+	}
+
+	// small gains against weak pokemon, big ones against strong pokemon
+	weak, _ := rateGame(even, Rating{Rating: 1200, Games: 1}, Record{Solved: true})
+	strong, _ := rateGame(even, Rating{Rating: 1800, Games: 1}, Record{Solved: true})
+	if weak.Rating-DefaultRating >= strong.Rating-DefaultRating {
+		t.Errorf("solving a weak pokemon gained %v, a strong one %v", weak.Rating-DefaultRating, strong.Rating-DefaultRating)
+	}
+}
+
+func TestMemoryRatingsSkipAnonymousSessions(t *testing.T) {
+	m := NewMemoryRatings()
+	m.RateSession(Record{PokemonID: 25, Solved: true})
+	if got := m.PokemonRating(25); got.Games != 0 || got.Rating != DefaultRating {
+		t.Fatalf("anonymous session rated the pokemon: %+v", got)
+	}
+	m.RateSession(Record{PlayerID: "p1", PokemonID: 25, Solved: true})
+	if got := m.PlayerRating("p1"); got.Games != 1 || got.Rating != DefaultRating+16 {
+		t.Fatalf("player rating %+v, want 1516 after one game", got)
+	}
+}
+
+func TestCloseness(t *testing.T) {
+	if got := Closeness(1500, 1500); got != 1 {
+		t.Errorf("even match = %v, want 1", got)
+	}
+	if got := Closeness(1500, 1500+ratingWindow); math.Abs(got-math.Exp(-1)) > 1e-12 {
+		t.Errorf("one window apart = %v, want 1/e", got)
+	}
+	if Closeness(1500, 1600) != Closeness(1600, 1500) {
+		t.Error("Closeness is not symmetric")
+	}
+	prev := 1.0
+	for gap := 50.0; gap <= 800; gap += 50 {
+		w := Closeness(1500, 1500+gap)
+		if w >= prev || w <= 0 {
+			t.Fatalf("gap %v weighs %v after %v, want strictly falling and positive", gap, w, prev)
+		}
+		prev = w
+	}
+}
@@ -0,0 +1,77 @@
+package quiz
+
+import "time"
+
+// EventType identifies what happened in a session
+type EventType string
+
+const (
+	EventGuess  EventType = "guess"
+	EventHint   EventType = "hint"
+	EventGiveUp EventType = "giveup"
+	EventTimeUp EventType = "timeup"
+)
+
+// maxEvents bounds the log so practice sessions (no cooldown) can't grow it without limit;
+// the outcome (correct guess, give-up, time-up) is logged even past it
+const maxEvents = 500
+
+// TokenEvents bounds the log sealed into a token session id (besides the first reveal of each hint kind),
+// which travels in every URL
+const TokenEvents = 16
+
+// Event is one timestamped entry of a session's log
+type Event struct {
+	Type    EventType `json:"type"`
+	At      time.Time `json:"at"`
+	Answer  string    `json:"answer,omitempty"`
+	Correct bool      `json:"correct,omitempty"`
+	Hint    string    `json:"hint,omitempty"` // hint kind, e.g. "type"
+}
+
+func (s *Session) record(e Event) {
+	if len(s.Events) >= maxEvents && !e.final() {
+		return
+	}
+	s.Events = append(s.Events, e)
+}
+
+// final reports whether e ends a session
+func (e Event) final() bool {
+	return e.Type == EventGiveUp || e.Type == EventTimeUp || (e.Type == EventGuess && e.Correct)
+}
+
+// trimEvents keeps the first reveal of each hint kind (HintsRevealed depends on them) and the latest n other events
+func trimEvents(events []Event, n int) []Event {
+	if len(events) <= n {
+		return events
+	}
+	seen := map[string]bool{}
+	firstHint := map[int]bool{}
+	for i, e := range events {
+		if e.Type == EventHint && e.Hint != "" && !seen[e.Hint] {
+			seen[e.Hint] = true
+			firstHint[i] = true
+		}
+	}
+	out := make([]Event, 0, n+len(firstHint))
+	for i, e := range events {
+		if firstHint[i] || i >= len(events)-n {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// HintsRevealed lists the distinct hint kinds revealed so far, in reveal order
+func (s *Session) HintsRevealed() []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, e := range s.Events {
+		if e.Type == EventHint && e.Hint != "" && !seen[e.Hint] {
+			seen[e.Hint] = true
+			out = append(out, e.Hint)
+		}
+	}
+	return out
+}
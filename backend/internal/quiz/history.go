@@ -0,0 +1,109 @@
+package quiz
+
+import (
+	"sync"
+	"time"
+)
+
+// Record is the outcome of one finished session, kept for statistics and pick weighting
+type Record struct {
+	Key        string        `json:"key"`
+	PlayerID   string        `json:"playerId,omitempty"`
+	PokemonID  int           `json:"pokemonId"`
+	Mode       string        `json:"mode,omitempty"`
+	Solved     bool          `json:"solved"`
+	GaveUp     bool          `json:"gaveUp,omitempty"`
+	TimedOut   bool          `json:"timedOut,omitempty"`
+	Missed     bool          `json:"missed,omitempty"`
+	Streak     int           `json:"streak,omitempty"` // endless: solved in a row when the session finished
+	Guesses    int           `json:"guesses"`
+	Hints      int           `json:"hints"`
+	Elapsed    time.Duration `json:"elapsed"`
+	FinishedAt time.Time     `json:"finishedAt"`
+}
+
+// RecordOf summarizes a finished session
+func RecordOf(s *Session) Record {
+	key := s.Key
+	if key == "" { // sessions stored before keys existed
+		key = s.ID
+	}
+	return Record{
+		Key:        key,
+		PlayerID:   s.PlayerID,
+		PokemonID:  s.PokemonID,
+		Mode:       s.Mode,
+		Solved:     s.Solved,
+		GaveUp:     s.GaveUp,
+		TimedOut:   s.TimedOut,
+		Missed:     s.Missed,
+		Streak:     s.runStreak(),
+		Guesses:    s.Guesses,
+		Hints:      s.HintsUsed,
+		Elapsed:    s.Elapsed(),
+		FinishedAt: s.FinishedAt,
+	}
+}
+
+// History is the log of finished sessions
+type History interface {
+	// Add stores r once and reports whether it was new; records with a known Key are ignored
+	Add(r Record) bool
+	// Player returns the records of one player, oldest first
+	Player(player string) []Record
+	// Each calls fn for every record, oldest first, until fn returns false
+	Each(fn func(Record) bool)
+}
+
+// MemoryHistory is an in-process History keeping the latest max records
+type MemoryHistory struct {
+	mu      sync.RWMutex
+	max     int
+	records []Record // a ring once max records are kept: the oldest is at next
+	next    int
+	keys    map[string]bool
+}
+
+func NewMemoryHistory(max int) *MemoryHistory {
+	return &MemoryHistory{max: max, keys: make(map[string]bool)}
+}
+
+func (h *MemoryHistory) Add(r Record) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.keys[r.Key] {
+		return false
+	}
+	h.keys[r.Key] = true
+	if h.max > 0 && len(h.records) >= h.max {
+		// overwrite the oldest record in place
+		delete(h.keys, h.records[h.next].Key)
+		h.records[h.next] = r
+		h.next = (h.next + 1) % len(h.records)
+		return true
+	}
+	h.records = append(h.records, r)
+	return true
+}
+
+func (h *MemoryHistory) Player(player string) []Record {
+	out := []Record{}
+	h.Each(func(r Record) bool {
+		if r.PlayerID == player {
+			out = append(out, r)
+		}
+		return true
+	})
+	return out
+}
+
+func (h *MemoryHistory) Each(fn func(Record) bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	n := len(h.records)
+	for i := 0; i < n; i++ {
+		if !fn(h.records[(h.next+i)%n]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package quiz
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// keysOf returns the record keys in order
+func keysOf(recs []Record) []string {
+	out := make([]string, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, r.Key)
+	}
+	return out
+}
+
+func TestMemoryHistoryKeepsLatest(t *testing.T) {
+	h := NewMemoryHistory(3)
+	for i := 1; i <= 5; i++ {
+		if !h.Add(Record{Key: fmt.Sprint(i), PlayerID: []string{"a", "b"}[i%2]}) {
+			t.Fatalf("record %d was not new", i)
+		}
+	}
+	if h.Add(Record{Key: "5"}) {
+		t.Error("a known key was added again")
+	}
+
+	var all []Record
+	h.Each(func(r Record) bool { all = append(all, r); return true })
+	if got := keysOf(all); !slices.Equal(got, []string{"3", "4", "5"}) {
+		t.Errorf("records = %v, want the latest 3 oldest first", got)
+	}
+	if got := keysOf(h.Player("b")); !slices.Equal(got, []string{"3", "5"}) {
+		t.Errorf("records of b = %v, want [3 5]", got)
+	}
+
+	// an evicted key is forgotten and may come back
+	if !h.Add(Record{Key: "1"}) {
+		t.Error("evicted key 1 was still known")
+	}
+	all = all[:0]
+	h.Each(func(r Record) bool { all = append(all, r); return len(all) < 2 })
+	if got := keysOf(all); !slices.Equal(got, []string{"4", "5"}) {
+		t.Errorf("Each stopped at %v, want [4 5]", got)
+	}
+}
+
+func openTestBolt(t *testing.T, path string) *BoltStore {
+	t.Helper()
+	s, err := OpenBoltStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestBoltHistoryPlayerIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.db")
+	s := openTestBolt(t, path)
+	for i, p := range []string{"a", "b", "a", "", "a"} {
+		s.Add(Record{Key: fmt.Sprint(i), PlayerID: p})
+	}
+	if got := keysOf(s.Player("a")); !slices.Equal(got, []string{"0", "2", "4"}) {
+		t.Errorf("records of a = %v", got)
+	}
+	if got := s.Player(""); len(got) != 0 {
+		t.Errorf("records without a player = %v, want none", keysOf(got))
+	}
+	if got := s.Player("nobody"); len(got) != 0 {
+		t.Errorf("records of an unknown player = %v", keysOf(got))
+	}
+
+	// a file written before the index existed is indexed when opened
+	if err := s.db.Update(func(tx *bolt.Tx) error { return tx.DeleteBucket(historyPlayersBucket) }); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	s = openTestBolt(t, path)
+	defer s.Close()
+	if got := keysOf(s.Player("a")); !slices.Equal(got, []string{"0", "2", "4"}) {
+		t.Errorf("records of a after reindexing = %v", got)
+	}
+	if got := keysOf(s.Player("b")); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("records of b after reindexing = %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package quiz
 
 import (
 	crand "crypto/rand"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	mrand "math/rand/v2"
@@ -10,16 +11,92 @@ import (
 
 var ErrTooSoon = errors.New("guess too soon")
 var ErrAlreadyFinished = errors.New("quiz already finished")
+var ErrTimeUp = errors.New("time limit exceeded")
 
-// AllowedGuessInterval defines throttle duration
+// AllowedGuessInterval defines the default throttle duration
 const AllowedGuessInterval = 5 * time.Second
 
-// RandomID picks one id from provided slice
-func RandomID(ids []int) int {
+// Time-attack limits accepted when starting a quiz
+const (
+	MinTimeLimit = 5 * time.Second
+	MaxTimeLimit = 10 * time.Minute
+)
+
+// Cooldown is the wait between guesses: Base, plus Step for every consecutive wrong guess, capped at Max
+type Cooldown struct {
+	Base time.Duration
+	Step time.Duration
+	Max  time.Duration
+}
+
+// Cooldown presets selectable when starting a quiz
+var (
+	CooldownNormal     = Cooldown{Base: AllowedGuessInterval}
+	CooldownPractice   = Cooldown{}
+	CooldownEscalating = Cooldown{Base: AllowedGuessInterval, Step: AllowedGuessInterval, Max: 30 * time.Second}
+)
+
+// CooldownPreset looks up a preset by name ("" means normal)
+func CooldownPreset(name string) (Cooldown, bool) {
+	switch name {
+	case "", "normal":
+		return CooldownNormal, true
+	case "practice":
+		return CooldownPractice, true
+	case "escalating":
+		return CooldownEscalating, true
+	}
+	return Cooldown{}, false
+}
+
+// CooldownName is the preset name of c, the inverse of CooldownPreset (false for a custom cooldown)
+func CooldownName(c Cooldown) (string, bool) {
+	for _, name := range []string{"normal", "practice", "escalating"} {
+		if p, _ := CooldownPreset(name); p == c {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// RandomID picks one id from provided slice using rng
+func RandomID(rng *mrand.Rand, ids []int) int {
 	if len(ids) == 0 {
 		return 0
 	}
-	return ids[mrand.IntN(len(ids))]
+	return ids[rng.IntN(len(ids))]
+}
+
+// WeightedIndex picks an index with probability proportional to its weight (weights <= 0 are never picked
+// unless all are, in which case the pick is uniform)
+func WeightedIndex(rng *mrand.Rand, weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		total += max(w, 0)
+	}
+	if total <= 0 {
+		return rng.IntN(len(weights))
+	}
+	x := rng.Float64() * total
+	for i, w := range weights {
+		x -= max(w, 0)
+		if x < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
+
+// NewSeed returns a random seed for a quiz started without one
+func NewSeed() uint64 {
+	var b [8]byte
+	_, _ = crand.Read(b[:])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// SeededRand returns the PRNG for one round of a quiz: the same seed and round always pick the same way
+func SeededRand(seed uint64, round int) *mrand.Rand {
+	return mrand.New(mrand.NewPCG(seed, uint64(round)))
 }
 
 func NewSession(pokemonID int, name string, regionKey string, types []string, allowMega, allowPrimal bool) *Session {
@@ -27,6 +104,7 @@ func NewSession(pokemonID int, name string, regionKey string, types []string, al
 	_, _ = crand.Read(sid)
 	return &Session{
 		ID:          hex.EncodeToString(sid),
+		Key:         hex.EncodeToString(sid),
 		PokemonID:   pokemonID,
 		PokemonName: name,
 		RegionKey:   regionKey,
@@ -35,51 +113,157 @@ func NewSession(pokemonID int, name string, regionKey string, types []string, al
 		LastGuessAt: time.Time{},
 		AllowMega:   allowMega,
 		AllowPrimal: allowPrimal,
+		Cooldown:    CooldownNormal,
 	}
 }
 
-// CanGuess enforces 5s interval
-func (s *Session) CanGuess() bool {
-	if s.Solved || s.GaveUp {
-		return false
+// GuessInterval returns the current wait after the last guess, escalated by consecutive wrong guesses
+func (s *Session) GuessInterval() time.Duration {
+	d := s.Cooldown.Base
+	if s.Cooldown.Step > 0 && s.WrongStreak > 1 {
+		d += s.Cooldown.Step * time.Duration(s.WrongStreak-1)
+	}
+	if s.Cooldown.Max > 0 && d > s.Cooldown.Max {
+		d = s.Cooldown.Max
 	}
+	return d
+}
+
+// RetryAfter returns how long until the next guess is accepted (0 if now)
+func (s *Session) RetryAfter() time.Duration {
 	if s.LastGuessAt.IsZero() {
-		return true
+		return 0
+	}
+	return max(0, s.GuessInterval()-time.Since(s.LastGuessAt))
+}
+
+// Deadline returns when the time limit runs out (zero if the session has none)
+func (s *Session) Deadline() time.Time {
+	if s.TimeLimit <= 0 {
+		return time.Time{}
+	}
+	return s.StartedAt.Add(s.TimeLimit)
+}
+
+// TimeRemaining returns the time left before the deadline (0 once passed or without a limit)
+func (s *Session) TimeRemaining() time.Duration {
+	if s.TimeLimit <= 0 || s.Finished() {
+		return 0
 	}
-	return time.Since(s.LastGuessAt) >= AllowedGuessInterval
+	return max(0, time.Until(s.Deadline()))
+}
+
+// CheckDeadline finishes the session as timed out once its deadline has passed and reports whether it timed out.
+// The deadline derives from StartedAt on the server, so it is settled lazily by whichever request comes first.
+func (s *Session) CheckDeadline() bool {
+	if !s.TimedOut && !s.Solved && !s.GaveUp && !s.Missed && s.TimeLimit > 0 && !time.Now().Before(s.Deadline()) {
+		s.TimedOut = true
+		s.FinishedAt = s.Deadline()
+		s.record(Event{Type: EventTimeUp, At: s.FinishedAt})
+	}
+	return s.TimedOut
+}
+
+// settleDeadline is CheckDeadline for janitors: it reports whether this call timed s out
+func (s *Session) settleDeadline() bool {
+	return !s.TimedOut && s.CheckDeadline()
+}
+
+// Finished reports whether the session was solved, given up, missed or has timed out
+func (s *Session) Finished() bool {
+	return s.CheckDeadline() || s.Solved || s.GaveUp || s.Missed
+}
+
+// CanGuess enforces the session's cooldown
+func (s *Session) CanGuess() bool {
+	if s.Finished() {
+		return false
+	}
+	return s.RetryAfter() == 0
 }
 
 // SubmitGuess update state
 func (s *Session) SubmitGuess(answer string) (correct bool, err error) {
-	if s.Solved || s.GaveUp {
+	if s.CheckDeadline() {
+		return false, ErrTimeUp
+	}
+	if s.Solved || s.GaveUp || s.Missed {
 		return false, ErrAlreadyFinished
 	}
 	if !s.CanGuess() {
 		return false, ErrTooSoon
 	}
 	s.LastGuessAt = time.Now()
+	s.Guesses++
+	if s.matches(answer) {
+		s.Solved = true
+		s.FinishedAt = s.LastGuessAt
+		s.record(Event{Type: EventGuess, At: s.LastGuessAt, Answer: answer, Correct: true})
+		return true, nil
+	}
+	s.WrongStreak++
+	s.record(Event{Type: EventGuess, At: s.LastGuessAt, Answer: answer})
+	if s.Endless() {
+		s.Missed = true
+		s.FinishedAt = s.LastGuessAt
+	}
+	return false, nil
+}
+
+// matches checks answer against english, japanese and additional accepted names
+func (s *Session) matches(answer string) bool {
 	normalized := normalize(answer)
 	// base english name
 	if normalized == normalize(s.PokemonName) {
-		s.Solved = true
-		return true, nil
+		return true
 	}
 	// display japanese
 	if s.DisplayName != "" && normalized == normalize(s.DisplayName) {
-		s.Solved = true
-		return true, nil
+		return true
 	}
 	// additional accepted answers
 	for _, a := range s.AcceptAnswers {
 		if normalized == normalize(a) {
-			s.Solved = true
-			return true, nil
+			return true
 		}
 	}
-	return false, nil
+	return false
+}
+
+func (s *Session) GiveUp() {
+	if s.Finished() {
+		return
+	}
+	s.GaveUp = true
+	s.FinishedAt = time.Now()
+	s.record(Event{Type: EventGiveUp, At: s.FinishedAt})
 }
 
-func (s *Session) GiveUp() { s.GaveUp = true }
+// UseHint records that a hint (kind may be empty) was revealed; re-opening a known kind is not counted again.
+// Finished sessions take no hints (ErrAlreadyFinished), so the recorded outcome and score stay fixed.
+func (s *Session) UseHint(kind string) error {
+	if s.Finished() {
+		return ErrAlreadyFinished
+	}
+	if kind != "" {
+		for _, k := range s.HintsRevealed() {
+			if k == kind {
+				return nil
+			}
+		}
+	}
+	s.HintsUsed++
+	s.record(Event{Type: EventHint, At: time.Now(), Hint: kind})
+	return nil
+}
+
+// Elapsed returns play time, frozen once the quiz is finished
+func (s *Session) Elapsed() time.Duration {
+	if s.FinishedAt.IsZero() {
+		return time.Since(s.StartedAt)
+	}
+	return s.FinishedAt.Sub(s.StartedAt)
+}
 
 func normalize(s string) string {
 	// simple lower ascii; could expand (e.g. remove hyphens)
@@ -0,0 +1,183 @@
+package quiz
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeededRandIsDeterministic(t *testing.T) {
+	for round := 0; round < 3; round++ {
+		a, b := SeededRand(7, round), SeededRand(7, round)
+		for i := 0; i < 100; i++ {
+			if x, y := a.Uint64(), b.Uint64(); x != y {
+				t.Fatalf("round %d draw %d: %d != %d", round, i, x, y)
+			}
+		}
+	}
+	// rounds of one seed are independent streams
+	if SeededRand(7, 0).Uint64() == SeededRand(7, 1).Uint64() {
+		t.Error("rounds 0 and 1 of seed 7 start the same")
+	}
+}
+
+func TestWeightedIndex(t *testing.T) {
+	weights := []float64{0, 1, 3, -2, 0.5}
+	counts := make([]int, len(weights))
+	for seed := uint64(0); seed < 2000; seed++ {
+		i := WeightedIndex(SeededRand(seed, 0), weights)
+		if j := WeightedIndex(SeededRand(seed, 0), weights); i != j {
+			t.Fatalf("seed %d: picked %d then %d", seed, i, j)
+		}
+		counts[i]++
+	}
+	if counts[0] != 0 || counts[3] != 0 {
+		t.Errorf("weights <= 0 were picked: %v", counts)
+	}
+	// index 2 weighs 3x index 1; allow generous slack for 2000 draws
+	if counts[2] < 2*counts[1] || counts[1] < counts[4] {
+		t.Errorf("picks do not follow the weights: %v", counts)
+	}
+
+	// all weights <= 0: uniform, still deterministic
+	if i, j := WeightedIndex(SeededRand(3, 0), []float64{0, 0, 0}), WeightedIndex(SeededRand(3, 0), []float64{0, 0, 0}); i != j {
+		t.Errorf("uniform fallback picked %d then %d", i, j)
+	}
+}
+
+func TestCooldownName(t *testing.T) {
+	for _, name := range []string{"normal", "practice", "escalating"} {
+		c, _ := CooldownPreset(name)
+		if got, ok := CooldownName(c); !ok || got != name {
+			t.Errorf("CooldownName(%s preset) = %q, %v", name, got, ok)
+		}
+	}
+	if _, ok := CooldownName(Cooldown{Base: time.Second}); ok {
+		t.Error("a custom cooldown has a preset name")
+	}
+}
+
+// lapsedSession returns a session whose one-minute time limit ran out a minute ago
+func lapsedSession() *Session {
+	sess := testSession(CooldownPractice)
+	sess.TimeLimit = time.Minute
+	sess.StartedAt = time.Now().Add(-2 * time.Minute)
+	return sess
+}
+
+func TestGuessAfterDeadline(t *testing.T) {
+	sess := lapsedSession()
+	correct, err := sess.SubmitGuess("pikachu")
+	if err != ErrTimeUp || correct {
+		t.Fatalf("SubmitGuess after the deadline = %v, %v; want false, ErrTimeUp", correct, err)
+	}
+	if !sess.TimedOut || sess.Solved || sess.Guesses != 0 {
+		t.Fatalf("late guess counted: timedOut=%v solved=%v guesses=%d", sess.TimedOut, sess.Solved, sess.Guesses)
+	}
+	if !sess.FinishedAt.Equal(sess.Deadline()) {
+		t.Fatalf("FinishedAt = %v, want the deadline %v", sess.FinishedAt, sess.Deadline())
+	}
+	if sess.Elapsed() != sess.TimeLimit {
+		t.Fatalf("Elapsed = %v, want the time limit", sess.Elapsed())
+	}
+	// further guesses keep reporting the time-up
+	if _, err := sess.SubmitGuess("pikachu"); err != ErrTimeUp {
+		t.Fatalf("second late guess: %v, want ErrTimeUp", err)
+	}
+}
+
+func TestGiveUpAfterDeadline(t *testing.T) {
+	sess := lapsedSession()
+	sess.GiveUp()
+	if sess.GaveUp || !sess.TimedOut {
+		t.Fatalf("give-up after the deadline: gaveUp=%v timedOut=%v, want a time-up", sess.GaveUp, sess.TimedOut)
+	}
+	if !sess.FinishedAt.Equal(sess.Deadline()) {
+		t.Fatalf("FinishedAt = %v, want the deadline %v", sess.FinishedAt, sess.Deadline())
+	}
+	if err := sess.UseHint("type"); err != ErrAlreadyFinished {
+		t.Fatalf("hint after the deadline: %v, want ErrAlreadyFinished", err)
+	}
+}
+
+func TestFinishBeforeDeadline(t *testing.T) {
+	sess := testSession(CooldownPractice)
+	sess.TimeLimit = time.Minute
+	if _, err := sess.SubmitGuess("pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	// a solved session never times out, even once its deadline passes
+	sess.StartedAt = sess.StartedAt.Add(-2 * time.Minute)
+	if sess.CheckDeadline() || sess.TimedOut {
+		t.Fatal("solved session timed out")
+	}
+}
+
+func TestTimeRemaining(t *testing.T) {
+	sess := testSession(CooldownPractice)
+	if got := sess.TimeRemaining(); got != 0 {
+		t.Errorf("without a limit: TimeRemaining = %v, want 0", got)
+	}
+	if !sess.Deadline().IsZero() {
+		t.Errorf("without a limit: Deadline = %v, want zero", sess.Deadline())
+	}
+
+	sess.TimeLimit = time.Minute
+	sess.StartedAt = time.Now().Add(-20 * time.Second)
+	if got := sess.TimeRemaining(); got <= 39*time.Second || got > 40*time.Second {
+		t.Errorf("20s into a minute: TimeRemaining = %v, want about 40s", got)
+	}
+
+	if got := lapsedSession().TimeRemaining(); got != 0 {
+		t.Errorf("after the deadline: TimeRemaining = %v, want 0", got)
+	}
+
+	sess.GiveUp()
+	if got := sess.TimeRemaining(); got != 0 {
+		t.Errorf("after giving up: TimeRemaining = %v, want 0", got)
+	}
+}
+
+func TestGuessInterval(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cooldown Cooldown
+		wrong    int
+		want     time.Duration
+	}{
+		{"practice", CooldownPractice, 5, 0},
+		{"normal", CooldownNormal, 0, 5 * time.Second},
+		{"normal ignores misses", CooldownNormal, 10, 5 * time.Second},
+		{"escalating first miss", CooldownEscalating, 1, 5 * time.Second},
+		{"escalating second miss", CooldownEscalating, 2, 10 * time.Second},
+		{"escalating fifth miss", CooldownEscalating, 5, 25 * time.Second},
+		{"escalating reaches cap", CooldownEscalating, 6, 30 * time.Second},
+		{"escalating stays capped", CooldownEscalating, 50, 30 * time.Second},
+		{"step without cap", Cooldown{Base: time.Second, Step: time.Second}, 100, 100 * time.Second},
+		{"cap below base", Cooldown{Base: 10 * time.Second, Max: 3 * time.Second}, 0, 3 * time.Second},
+	} {
+		sess := testSession(tc.cooldown)
+		sess.WrongStreak = tc.wrong
+		if got := sess.GuessInterval(); got != tc.want {
+			t.Errorf("%s: GuessInterval = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWrongGuessesEscalateCooldown(t *testing.T) {
+	sess := testSession(CooldownEscalating)
+	for i, want := range []time.Duration{5, 10, 15, 20, 25, 30, 30} {
+		sess.LastGuessAt = time.Time{} // skip the wait
+		if _, err := sess.SubmitGuess("raichu"); err != nil {
+			t.Fatalf("guess %d: %v", i, err)
+		}
+		if got := sess.GuessInterval(); got != want*time.Second {
+			t.Errorf("after %d misses: GuessInterval = %v, want %v", i+1, got, want*time.Second)
+		}
+		if got := sess.RetryAfter(); got <= 0 || got > want*time.Second {
+			t.Errorf("after %d misses: RetryAfter = %v, want up to %v", i+1, got, want*time.Second)
+		}
+	}
+	if _, err := sess.SubmitGuess("pikachu"); err != ErrTooSoon {
+		t.Fatalf("guess within the cooldown: %v, want ErrTooSoon", err)
+	}
+}
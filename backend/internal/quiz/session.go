@@ -1,12 +1,10 @@
 package quiz
 
-import (
-	"sync"
-	"time"
-)
+import "time"
 
 type Session struct {
 	ID            string
+	Key           string // stable identity; ID is re-issued by token stores
 	PokemonID     int
 	PokemonName   string
 	DisplayName   string
@@ -15,28 +13,44 @@ type Session struct {
 	Types         []string
 	StartedAt     time.Time
 	LastGuessAt   time.Time
+	FinishedAt    time.Time
+	Guesses       int
+	WrongStreak   int // consecutive wrong guesses, drives escalating cooldowns
+	Cooldown      Cooldown
+	Events        []Event
+	HintsUsed     int
 	Solved        bool
 	GaveUp        bool
+	TimedOut      bool          // the time limit ran out before a solve or give-up
+	Missed        bool          // a wrong guess ended an endless streak
+	TimeLimit     time.Duration // hard limit from StartedAt (0 = none)
 	AllowMega     bool
 	AllowPrimal   bool
+	Regions       []string // region keys the quiz was started with (empty = all)
+	Style         string   // artwork style (poke.Style) the silhouette is drawn from
+	Ambiguity     string   // how near-identical silhouettes were handled when picking
+	Seed          uint64   // drives the pick (and the rest of an endless run), so the quiz can be replayed
+	Seeded        bool     // the seed was given by the client, i.e. this is a replay
+	Partial       bool     // PokeAPI could not return some candidates, so the seed does not reproduce the pick
+	Pick          string   // selection strategy the pokemon was drawn with
+	MinDifficulty float64  // only pokemon rated at least this hard were candidates
+	Difficulty    float64  // rating of the pokemon when the session started (scores stay stable)
+	Mode          string   // "" for a single quiz, ModeEndless or ModeDaily
+	PlayerID      string   // client-chosen player id (daily challenge, pick weighting)
+	Daily         string   // daily: challenge date (YYYY-MM-DD)
+	Streak        int      // endless: pokemon solved in a row before this session
+	BestStreak    int      // endless: best streak carried over from earlier sessions and runs
+	Used          []int    // endless: pokemon ids asked earlier in the streak
+	NextID        string   // endless: the session that continues the streak once this one is solved
 }
 
-type Store struct {
-	mu sync.RWMutex
-	m  map[string]*Session
-}
-
-func NewStore() *Store { return &Store{m: make(map[string]*Session)} }
-
-func (s *Store) Get(id string) (*Session, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	v, ok := s.m[id]
-	return v, ok
-}
-
-func (s *Store) Set(sess *Session) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.m[sess.ID] = sess
+// Clone returns a copy that shares no mutable state with s
+func (s *Session) Clone() *Session {
+	cp := *s
+	cp.AcceptAnswers = append([]string(nil), s.AcceptAnswers...)
+	cp.Types = append([]string(nil), s.Types...)
+	cp.Regions = append([]string(nil), s.Regions...)
+	cp.Events = append([]Event(nil), s.Events...)
+	cp.Used = append([]int(nil), s.Used...)
+	return &cp
 }
@@ -0,0 +1,198 @@
+package quiz
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore keeps quiz sessions by ID.
+// Get returns a snapshot; every mutation of a stored session must go through Update.
+type SessionStore interface {
+	Get(id string) (*Session, bool)
+	// Set stores a new session (some stores assign sess.ID here)
+	Set(sess *Session)
+	// Update runs fn on the session atomically with respect to other updates of it.
+	// Changes are kept only when fn returns nil; the returned snapshot reflects fn either way
+	// (ErrSessionNotFound when the id is unknown or expired).
+	Update(id string, fn func(*Session) error) (*Session, error)
+	Delete(id string)
+}
+
+// DeadlineSettler is implemented by stores whose janitor settles the lapsed time limits of sessions no request
+// touches any more: fn gets a snapshot of every session the janitor timed out, once the timeout is stored
+type DeadlineSettler interface {
+	OnTimeUp(fn func(*Session))
+}
+
+// Replayable reports whether old snapshots of s's sessions can be replayed (TokenStore): the outcomes it reports are
+// then not trustworthy enough to be scored, rated or booked
+func Replayable(s SessionStore) bool {
+	r, ok := s.(interface{ Replayable() bool })
+	return ok && r.Replayable()
+}
+
+// MemoryStore is an in-memory SessionStore. Sessions expire after ttl without access
+// and the least recently used session is evicted once maxSessions is reached.
+type MemoryStore struct {
+	ttl         time.Duration
+	maxSessions int
+
+	mu     sync.Mutex
+	m      map[string]*list.Element
+	lru    *list.List // front = most recently used
+	timeUp atomic.Pointer[func(*Session)]
+	stop   chan struct{}
+	once   sync.Once
+}
+
+type memoryEntry struct {
+	sess    *Session
+	touched time.Time
+}
+
+// NewMemoryStore creates a store; ttl <= 0 disables expiry and maxSessions <= 0 disables the cap
+func NewMemoryStore(ttl time.Duration, maxSessions int) *MemoryStore {
+	return &MemoryStore{ttl: ttl, maxSessions: maxSessions, m: make(map[string]*list.Element), lru: list.New(), stop: make(chan struct{})}
+}
+
+func (s *MemoryStore) Get(id string) (*Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.m[id]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryEntry)
+	if s.expired(e, time.Now()) {
+		s.remove(el)
+		return nil, false
+	}
+	e.touched = time.Now()
+	s.lru.MoveToFront(el)
+	return e.sess.Clone(), true
+}
+
+func (s *MemoryStore) Set(sess *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.m[sess.ID]; ok {
+		el.Value = &memoryEntry{sess: sess, touched: time.Now()}
+		s.lru.MoveToFront(el)
+		return
+	}
+	for s.maxSessions > 0 && s.lru.Len() >= s.maxSessions {
+		s.remove(s.lru.Back())
+	}
+	s.m[sess.ID] = s.lru.PushFront(&memoryEntry{sess: sess, touched: time.Now()})
+}
+
+func (s *MemoryStore) Update(id string, fn func(*Session) error) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.m[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	e := el.Value.(*memoryEntry)
+	if s.expired(e, time.Now()) {
+		s.remove(el)
+		return nil, ErrSessionNotFound
+	}
+	e.touched = time.Now()
+	s.lru.MoveToFront(el)
+
+	work := e.sess.Clone()
+	if err := fn(work); err != nil {
+		return work, err
+	}
+	e.sess = work
+	return work.Clone(), nil
+}
+
+func (s *MemoryStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.m[id]; ok {
+		s.remove(el)
+	}
+}
+
+// Len returns the number of stored sessions (including expired ones not yet collected)
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// OnTimeUp sets the function the janitor calls for each session it timed out
+func (s *MemoryStore) OnTimeUp(fn func(*Session)) {
+	s.timeUp.Store(&fn)
+}
+
+// StartJanitor removes expired sessions and settles lapsed time limits every interval until Close is called
+func (s *MemoryStore) StartJanitor(interval time.Duration) {
+	if s.ttl <= 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				s.collect(time.Now())
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the janitor
+func (s *MemoryStore) Close() { s.once.Do(func() { close(s.stop) }) }
+
+// collect drops expired sessions (the LRU list is ordered by access, so it stops at the first live one), then
+// times out the live sessions whose deadline passed and hands them to the OnTimeUp function
+func (s *MemoryStore) collect(now time.Time) {
+	s.mu.Lock()
+	for el := s.lru.Back(); el != nil; el = s.lru.Back() {
+		if !s.expired(el.Value.(*memoryEntry), now) {
+			break
+		}
+		s.remove(el)
+	}
+	var lapsed []*Session
+	for _, el := range s.m {
+		e := el.Value.(*memoryEntry)
+		if e.sess.TimeLimit <= 0 {
+			continue
+		}
+		// settle on a copy, like Update, so snapshots handed out earlier stay untouched
+		work := e.sess.Clone()
+		if work.settleDeadline() {
+			e.sess = work
+			lapsed = append(lapsed, work.Clone())
+		}
+	}
+	s.mu.Unlock()
+
+	if fn := s.timeUp.Load(); fn != nil {
+		for _, sess := range lapsed {
+			(*fn)(sess)
+		}
+	}
+}
+
+func (s *MemoryStore) expired(e *memoryEntry, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(e.touched) > s.ttl
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.m, el.Value.(*memoryEntry).sess.ID)
+}
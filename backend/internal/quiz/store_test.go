@@ -0,0 +1,453 @@
+package quiz
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testStores returns a fresh instance of every SessionStore
+func testStores(t *testing.T) map[string]SessionStore {
+	t.Helper()
+	db, err := OpenBoltStore(filepath.Join(t.TempDir(), "sessions.db"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	ts, err := NewTokenStore(make([]byte, 32), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]SessionStore{
+		"memory": NewMemoryStore(time.Hour, 0),
+		"bolt":   db,
+		"token":  ts,
+	}
+}
+
+func testSession(cooldown Cooldown) *Session {
+	sess := NewSession(25, "pikachu", "kanto", []string{"electric"}, false, false)
+	sess.DisplayName = "ピカチュウ"
+	sess.Cooldown = cooldown
+	return sess
+}
+
+// hammer runs fn from n goroutines released at once and returns the snapshots of the calls that succeeded
+func hammer(n int, fn func(i int) (*Session, error)) (ok []*Session, errs []error) {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			sess, err := fn(i)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				ok = append(ok, sess)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return ok, errs
+}
+
+func TestConcurrentGuessesWithinCooldown(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			sess := testSession(CooldownNormal)
+			store.Set(sess)
+			id := sess.ID
+
+			ok, errs := hammer(32, func(int) (*Session, error) {
+				return store.Update(id, func(s *Session) error {
+					_, err := s.SubmitGuess("raichu")
+					return err
+				})
+			})
+			if len(ok) != 1 {
+				t.Fatalf("%d guesses accepted inside the cooldown, want 1", len(ok))
+			}
+			for _, err := range errs {
+				// the token store refuses the consumed token; the others enforce the cooldown
+				if !errors.Is(err, ErrTooSoon) && !errors.Is(err, ErrSessionNotFound) {
+					t.Errorf("unexpected error %v", err)
+				}
+			}
+
+			got, found := store.Get(ok[0].ID)
+			if !found {
+				t.Fatal("session missing after update")
+			}
+			if got.Guesses != 1 || got.WrongStreak != 1 {
+				t.Errorf("guesses = %d, wrong streak = %d; want 1, 1", got.Guesses, got.WrongStreak)
+			}
+		})
+	}
+}
+
+func TestConcurrentGuessesAndGiveUps(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			sess := testSession(CooldownPractice)
+			store.Set(sess)
+			id := sess.ID
+
+			ok, _ := hammer(32, func(i int) (*Session, error) {
+				return store.Update(id, func(s *Session) error {
+					if i%2 == 0 {
+						s.GiveUp()
+						return nil
+					}
+					_, err := s.SubmitGuess("pikachu")
+					return err
+				})
+			})
+			if len(ok) == 0 {
+				t.Fatal("no update succeeded")
+			}
+
+			final := ok[0]
+			if name != "token" { // every other store keeps one session; read its final state
+				var found bool
+				if final, found = store.Get(id); !found {
+					t.Fatal("session missing after updates")
+				}
+			}
+			if final.Solved == final.GaveUp {
+				t.Fatalf("solved = %v, gave up = %v; want exactly one outcome", final.Solved, final.GaveUp)
+			}
+			if final.Solved && final.Guesses != 1 {
+				t.Errorf("guesses = %d after the solve, want 1", final.Guesses)
+			}
+			if final.GaveUp && final.Guesses != 0 {
+				t.Errorf("guesses = %d after the give-up, want 0", final.Guesses)
+			}
+			outcomes := 0
+			for _, e := range final.Events {
+				if e.Type == EventGiveUp || (e.Type == EventGuess && e.Correct) {
+					outcomes++
+				}
+			}
+			if outcomes != 1 {
+				t.Errorf("%d outcome events logged, want 1", outcomes)
+			}
+		})
+	}
+}
+
+func TestTokenNotReusableAfterUpdate(t *testing.T) {
+	store, err := NewTokenStore(make([]byte, 32), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := testSession(CooldownPractice)
+	store.Set(sess)
+	first := sess.ID
+
+	next, err := store.Update(first, func(s *Session) error {
+		_, err := s.SubmitGuess("raichu")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.ID == first {
+		t.Fatal("update did not re-issue the token")
+	}
+
+	if _, err := store.Update(first, func(s *Session) error { return nil }); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("update with the used token: err = %v, want ErrSessionNotFound", err)
+	}
+	if _, found := store.Get(first); found {
+		t.Error("get with the used token succeeded")
+	}
+	if got, found := store.Get(next.ID); !found || got.Guesses != 1 {
+		t.Errorf("get with the new token: found = %v, guesses = %v", found, got)
+	}
+
+	// once the session is given up, no earlier token may act on it, e.g. to guess the revealed answer
+	done, err := store.Update(next.ID, func(s *Session) error { s.GiveUp(); return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Update(next.ID, func(s *Session) error {
+		_, err := s.SubmitGuess("pikachu")
+		return err
+	}); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("guess with the pre-give-up token: err = %v, want ErrSessionNotFound", err)
+	}
+	if got, found := store.Get(done.ID); !found || !got.GaveUp {
+		t.Error("the finished token should still show the outcome")
+	}
+}
+
+func TestTokenRefusesStaleSnapshotOfFinishedSession(t *testing.T) {
+	store, err := NewTokenStore(make([]byte, 32), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := testSession(CooldownPractice)
+	store.Set(sess)
+	stale := *sess
+	store.Set(&stale) // a second, never consumed token of the same session
+	if _, err := store.Update(sess.ID, func(s *Session) error { s.GiveUp(); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Update(stale.ID, func(s *Session) error { return nil }); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("update with a stale token of a finished session: err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestTokenStaysSmallWithManyGuesses(t *testing.T) {
+	store, err := NewTokenStore(make([]byte, 32), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := testSession(CooldownPractice)
+	store.Set(sess)
+	id := sess.ID
+
+	update := func(fn func(*Session) error) {
+		t.Helper()
+		next, err := store.Update(id, fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id = next.ID
+	}
+	update(func(s *Session) error { return s.UseHint("type") })
+	for i := 0; i < 200; i++ {
+		update(func(s *Session) error {
+			_, err := s.SubmitGuess("raichu")
+			return err
+		})
+	}
+	update(func(s *Session) error { s.GiveUp(); return nil })
+
+	if len(id) > 4096 {
+		t.Errorf("token is %d bytes after 200 guesses, want at most 4096", len(id))
+	}
+	got, found := store.Get(id)
+	if !found {
+		t.Fatal("session missing")
+	}
+	if got.Guesses != 200 || !got.GaveUp {
+		t.Errorf("guesses = %d, gave up = %v; want 200, true", got.Guesses, got.GaveUp)
+	}
+	if len(got.Events) > TokenEvents+1 {
+		t.Errorf("%d events sealed, want at most %d", len(got.Events), TokenEvents+1)
+	}
+	if r := got.HintsRevealed(); len(r) != 1 || r[0] != "type" {
+		t.Errorf("hints revealed = %v, want [type]", r)
+	}
+	if last := got.Events[len(got.Events)-1]; last.Type != EventGiveUp {
+		t.Errorf("last event = %s, want giveup", last.Type)
+	}
+}
+
+func TestOutcomeLoggedPastEventCap(t *testing.T) {
+	sess := testSession(CooldownPractice)
+	for i := 0; i < maxEvents+10; i++ {
+		if _, err := sess.SubmitGuess("raichu"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sess.SubmitGuess("pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sess.Events) != maxEvents+1 {
+		t.Fatalf("%d events, want %d", len(sess.Events), maxEvents+1)
+	}
+	if last := sess.Events[len(sess.Events)-1]; !last.Correct {
+		t.Error("the solving guess was not logged")
+	}
+}
+
+// settler is a store whose janitor settles lapsed time limits
+type settler interface {
+	SessionStore
+	DeadlineSettler
+	collect(now time.Time)
+}
+
+// boltSettler adapts BoltStore.collect, which reports errors, for the janitor test
+type boltSettler struct{ *BoltStore }
+
+func (s boltSettler) collect(now time.Time) {
+	if err := s.BoltStore.collect(now); err != nil {
+		panic(err)
+	}
+}
+
+func TestJanitorSettlesLapsedDeadlines(t *testing.T) {
+	stores := testStores(t)
+	for name, store := range map[string]settler{
+		"memory": stores["memory"].(*MemoryStore),
+		"bolt":   boltSettler{stores["bolt"].(*BoltStore)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var timedUp []*Session
+			store.OnTimeUp(func(s *Session) { timedUp = append(timedUp, s) })
+
+			lapsed := testSession(Cooldown{})
+			lapsed.TimeLimit = time.Minute
+			lapsed.StartedAt = time.Now().Add(-2 * time.Minute)
+			running := testSession(Cooldown{})
+			running.TimeLimit = time.Hour
+			untimed := testSession(Cooldown{})
+			untimed.StartedAt = lapsed.StartedAt
+			for _, s := range []*Session{lapsed, running, untimed} {
+				store.Set(s)
+			}
+
+			store.collect(time.Now())
+			if len(timedUp) != 1 || timedUp[0].ID != lapsed.ID || !timedUp[0].TimedOut {
+				t.Fatalf("janitor timed out %v, want only %s", timedUp, lapsed.ID)
+			}
+			got, ok := store.Get(lapsed.ID)
+			if !ok || !got.TimedOut || !got.FinishedAt.Equal(lapsed.Deadline()) {
+				t.Fatalf("stored session not settled: %+v", got)
+			}
+			for _, s := range []*Session{running, untimed} {
+				if got, _ := store.Get(s.ID); got.Finished() {
+					t.Fatalf("session %s finished by the janitor", s.ID)
+				}
+			}
+
+			// a settled session is reported once
+			store.collect(time.Now())
+			if len(timedUp) != 1 {
+				t.Fatalf("janitor reported %d time-ups, want 1", len(timedUp))
+			}
+		})
+	}
+}
+
+// age moves the last access of a stored session d into the past
+func (s *MemoryStore) age(id string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.m[id].Value.(*memoryEntry)
+	e.touched = e.touched.Add(-d)
+}
+
+func TestMemoryStoreExpiresOnRead(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 0)
+	stale, live := testSession(Cooldown{}), testSession(Cooldown{})
+	store.Set(stale)
+	store.Set(live)
+	store.age(stale.ID, 2*time.Hour)
+	store.age(live.ID, 30*time.Minute)
+
+	if _, ok := store.Get(stale.ID); ok {
+		t.Fatal("Get returned an expired session")
+	}
+	if _, err := store.Update(stale.ID, func(*Session) error { return nil }); err != ErrSessionNotFound {
+		t.Fatalf("Update of an expired session: %v, want ErrSessionNotFound", err)
+	}
+	if store.Len() != 1 {
+		t.Fatalf("Len = %d, want the expired session removed on read", store.Len())
+	}
+
+	// a read refreshes the session, so it outlives its original ttl
+	if _, ok := store.Get(live.ID); !ok {
+		t.Fatal("live session missing")
+	}
+	store.age(live.ID, 45*time.Minute)
+	if _, ok := store.Get(live.ID); !ok {
+		t.Fatal("Get did not refresh the session")
+	}
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(0, 3)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		sess := testSession(Cooldown{})
+		store.Set(sess)
+		ids = append(ids, sess.ID)
+	}
+	// reading the oldest makes the second the least recently used
+	store.Get(ids[0])
+	if _, err := store.Update(ids[2], func(*Session) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	extra := testSession(Cooldown{})
+	store.Set(extra)
+	if store.Len() != 3 {
+		t.Fatalf("Len = %d, want the cap of 3", store.Len())
+	}
+	if _, ok := store.Get(ids[1]); ok {
+		t.Fatal("least recently used session survived")
+	}
+	for _, id := range []string{ids[0], ids[2], extra.ID} {
+		if _, ok := store.Get(id); !ok {
+			t.Fatalf("session %s evicted out of order", id)
+		}
+	}
+
+	// replacing a stored session does not evict
+	store.Set(extra)
+	if store.Len() != 3 {
+		t.Fatalf("Len = %d after replacing a session, want 3", store.Len())
+	}
+}
+
+func TestMemoryStoreCollect(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 0)
+	var ids []string
+	for i := 0; i < 4; i++ {
+		sess := testSession(Cooldown{})
+		store.Set(sess)
+		ids = append(ids, sess.ID)
+	}
+	store.age(ids[0], 3*time.Hour)
+	store.age(ids[1], 2*time.Hour)
+
+	store.collect(time.Now())
+	if store.Len() != 2 {
+		t.Fatalf("Len = %d after collect, want 2", store.Len())
+	}
+	for _, id := range ids[2:] {
+		if _, ok := store.Get(id); !ok {
+			t.Fatalf("collect removed live session %s", id)
+		}
+	}
+
+	// everything expires once the clock passes the ttl
+	store.collect(time.Now().Add(2 * time.Hour))
+	if store.Len() != 0 {
+		t.Fatalf("Len = %d after collecting everything, want 0", store.Len())
+	}
+}
+
+func TestMemoryStoreDelete(t *testing.T) {
+	store := NewMemoryStore(0, 2)
+	a, b, c := testSession(Cooldown{}), testSession(Cooldown{}), testSession(Cooldown{})
+	store.Set(a)
+	store.Set(b)
+	store.Delete(a.ID)
+	store.Delete("missing")
+	if _, ok := store.Get(a.ID); ok {
+		t.Fatal("deleted session still readable")
+	}
+	if _, err := store.Update(a.ID, func(*Session) error { return nil }); err != ErrSessionNotFound {
+		t.Fatalf("Update of a deleted session: %v, want ErrSessionNotFound", err)
+	}
+	// the freed slot is reused without evicting b
+	store.Set(c)
+	if _, ok := store.Get(b.ID); !ok || store.Len() != 2 {
+		t.Fatalf("Len = %d, b present = %v after reusing a deleted slot", store.Len(), ok)
+	}
+}
@@ -0,0 +1,60 @@
+package quiz
+
+// ModeEndless chains every solve into a new session with the same settings; a wrong guess or give-up ends the streak
+const ModeEndless = "endless"
+
+// Endless reports whether s is part of an endless streak
+func (s *Session) Endless() bool {
+	return s.Mode == ModeEndless
+}
+
+// StreakLength returns the pokemon solved in a row so far, counting this session once solved
+func (s *Session) StreakLength() int {
+	if s.Solved {
+		return s.Streak + 1
+	}
+	return s.Streak
+}
+
+// runStreak is the streak recorded in history: StreakLength for endless sessions, else 0
+func (s *Session) runStreak() int {
+	if !s.Endless() {
+		return 0
+	}
+	return s.StreakLength()
+}
+
+// BestStreak returns the longest endless streak in records (e.g. History.Player)
+func BestStreak(records []Record) int {
+	best := 0
+	for _, r := range records {
+		best = max(best, r.Streak)
+	}
+	return best
+}
+
+// Best returns the best streak of the player, including the current one
+func (s *Session) Best() int {
+	return max(s.BestStreak, s.StreakLength())
+}
+
+// StreakUsed returns the pokemon ids already asked in this streak, including the current one
+func (s *Session) StreakUsed() []int {
+	return append(append([]int(nil), s.Used...), s.PokemonID)
+}
+
+// Continue carries the streak and settings of the solved session s over to next
+func (s *Session) Continue(next *Session) {
+	next.Mode = s.Mode
+	next.Streak = s.StreakLength()
+	next.BestStreak = s.Best()
+	next.Used = s.StreakUsed()
+	next.Seed = s.Seed
+	next.Seeded = s.Seeded
+	next.Partial = next.Partial || s.Partial
+	next.Pick = s.Pick
+	next.PlayerID = s.PlayerID
+	next.Style = s.Style
+	next.Cooldown = s.Cooldown
+	next.TimeLimit = s.TimeLimit
+}
@@ -0,0 +1,36 @@
+package quiz
+
+import "testing"
+
+func TestBestStreakFromHistory(t *testing.T) {
+	h := NewMemoryHistory(0)
+	run := testSession(CooldownPractice)
+	run.Mode = ModeEndless
+	run.PlayerID = "p1"
+	for i := 0; i < 3; i++ {
+		if ok, err := run.SubmitGuess("pikachu"); !ok || err != nil {
+			t.Fatalf("solve %d: %v %v", i, ok, err)
+		}
+		h.Add(RecordOf(run))
+		next := testSession(CooldownPractice)
+		run.Continue(next)
+		run = next
+	}
+	if _, err := run.SubmitGuess("eevee"); err != nil {
+		t.Fatal(err)
+	}
+	h.Add(RecordOf(run))
+
+	// a single quiz is never counted as a streak
+	single := testSession(CooldownPractice)
+	single.PlayerID = "p1"
+	single.SubmitGuess("pikachu")
+	h.Add(RecordOf(single))
+
+	if got := BestStreak(h.Player("p1")); got != 3 {
+		t.Errorf("best streak = %d, want 3", got)
+	}
+	if got := BestStreak(h.Player("p2")); got != 0 {
+		t.Errorf("best streak of an unknown player = %d, want 0", got)
+	}
+}
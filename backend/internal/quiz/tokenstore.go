@@ -0,0 +1,201 @@
+package quiz
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// tokenAAD binds tokens to this format version
+var tokenAAD = []byte("psq-session-v1")
+
+var ErrInvalidToken = errors.New("invalid session token")
+
+// TokenStore is a stateless SessionStore for serverless deployments: the whole Session is sealed
+// (AES-256-GCM) into its ID. Set and Update re-issue the token into sess.ID, so responses must hand
+// the new ID back to the client. Only the latest TokenEvents events are kept (see trimEvents).
+//
+// There is no replay protection: a superseded token stays valid until it expires. Each instance remembers the
+// tokens it superseded and the sessions it saw finish, which stops double submits against one instance, but
+// another instance, or this one after a restart, accepts any earlier token (e.g. one from before a give-up).
+// The store is therefore Replayable, and its sessions are played unranked.
+type TokenStore struct {
+	aead cipher.AEAD
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	consumed  map[[16]byte]time.Time // token hash -> when it can be forgotten
+	finished  map[string]time.Time   // Session.Key of finished sessions -> when it can be forgotten
+	lastPrune time.Time
+}
+
+type tokenPayload struct {
+	Session *Session  `json:"s"`
+	Expires time.Time `json:"e"`
+}
+
+// NewTokenStore creates a store from a 32-byte key; ttl bounds how long a token stays valid
+func NewTokenStore(key []byte, ttl time.Duration) (*TokenStore, error) {
+	if len(key) != 32 {
+		return nil, errors.New("session token key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenStore{aead: aead, ttl: ttl, consumed: make(map[[16]byte]time.Time), finished: make(map[string]time.Time)}, nil
+}
+
+// Replayable is always true: see TokenStore
+func (s *TokenStore) Replayable() bool {
+	return true
+}
+
+func (s *TokenStore) Get(id string) (*Session, bool) {
+	sess, err := s.open(id)
+	if err != nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	replay := s.replayLocked(id, sess)
+	s.mu.Unlock()
+	if replay {
+		return nil, false
+	}
+	sess.ID = id
+	return sess, true
+}
+
+// Set seals sess into a new token and stores it in sess.ID
+func (s *TokenStore) Set(sess *Session) {
+	if err := s.seal(sess); err != nil {
+		log.Printf("token store encode: %v", err)
+	}
+}
+
+// Update applies fn to the session in token id and, on success, retires id and re-issues the token.
+// Checking and retiring happen under one lock, so concurrent updates with the same token cannot both succeed
+// on this instance. Tokens of a session known to be finished are refused unless they carry that outcome.
+func (s *TokenStore) Update(id string, fn func(*Session) error) (*Session, error) {
+	sess, err := s.open(id)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	sess.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.replayLocked(id, sess) {
+		return nil, ErrSessionNotFound
+	}
+	if err := fn(sess); err != nil {
+		return sess, err
+	}
+	if err := s.seal(sess); err != nil {
+		return nil, err
+	}
+	forget := time.Now().Add(s.ttl)
+	s.consumeLocked(id, forget)
+	if sess.Finished() && sess.Key != "" {
+		s.finished[sess.Key] = forget
+	}
+	return sess, nil
+}
+
+// seal encrypts sess into a fresh token stored in sess.ID
+func (s *TokenStore) seal(sess *Session) error {
+	exp := time.Now().Add(s.ttl)
+
+	// the sealed copy carries no ID (the token itself is the ID) and a trimmed event log, since the token
+	// travels in URL paths and must stay small however many guesses a practice session takes
+	cp := *sess
+	cp.ID = ""
+	cp.Events = trimEvents(sess.Events, TokenEvents)
+	data, err := json.Marshal(tokenPayload{Session: &cp, Expires: exp})
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	_, _ = crand.Read(nonce)
+	sealed := s.aead.Seal(nonce, nonce, data, tokenAAD)
+	sess.ID = base64.RawURLEncoding.EncodeToString(sealed)
+	return nil
+}
+
+// Delete retires a token so it can no longer be used
+func (s *TokenStore) Delete(id string) {
+	if _, err := s.open(id); err == nil {
+		s.mu.Lock()
+		s.consumeLocked(id, time.Now().Add(s.ttl))
+		s.mu.Unlock()
+	}
+}
+
+func (s *TokenStore) open(token string) (*Session, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) < s.aead.NonceSize() {
+		return nil, ErrInvalidToken
+	}
+	n := s.aead.NonceSize()
+	data, err := s.aead.Open(nil, raw[:n], raw[n:], tokenAAD)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var p tokenPayload
+	if err := json.Unmarshal(data, &p); err != nil || p.Session == nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().After(p.Expires) {
+		return nil, ErrInvalidToken
+	}
+	return p.Session, nil
+}
+
+// replayLocked reports whether token was superseded, or holds an unfinished snapshot of a session that has
+// finished since; s.mu must be held
+func (s *TokenStore) replayLocked(token string, sess *Session) bool {
+	if _, ok := s.consumed[tokenHash(token)]; ok {
+		return true
+	}
+	if _, ok := s.finished[sess.Key]; ok && sess.Key != "" {
+		return !sess.Finished()
+	}
+	return false
+}
+
+// consumeLocked remembers token until forget, after which it would have expired anyway; s.mu must be held
+func (s *TokenStore) consumeLocked(token string, forget time.Time) {
+	now := time.Now()
+	if now.Sub(s.lastPrune) > time.Minute {
+		for k, t := range s.consumed {
+			if now.After(t) {
+				delete(s.consumed, k)
+			}
+		}
+		for k, t := range s.finished {
+			if now.After(t) {
+				delete(s.finished, k)
+			}
+		}
+		s.lastPrune = now
+	}
+	s.consumed[tokenHash(token)] = forget
+}
+
+func tokenHash(token string) [16]byte {
+	sum := sha256.Sum256([]byte(token))
+	var h [16]byte
+	copy(h[:], sum[:16])
+	return h
+}
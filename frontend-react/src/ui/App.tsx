@@ -1,13 +1,65 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { StartScreen } from './StartScreen';
 import { QuizScreen } from './QuizScreen';
 import { ResultScreen } from './ResultScreen';
 
+// 正解時・ギブアップ時にバックエンドが返す答えの詳細
+export type QuizResult = {
+  pokemonId: number;
+  speciesId: number;
+  name: string;
+  nameJa: string;
+  nameEn: string;
+  form: string;
+  types: string[];
+  region: string;
+  genus: string;
+  dexEntry: string;
+  streak?: Streak;
+  daily?: string;
+  seed: string;
+  replay?: StartSettings; // /api/quiz/start にそのまま送ると同じ問題を再現できる (再現できない設定では無し)
+  score: number;
+  ranked: boolean; // false ならスコア・レーティングなし (token モード)
+  difficulty: number;
+  rating?: Rating; // playerId 付きのセッションのみ
+  sessionId: string;
+};
+
+// 出題設定 (シード込み)。結果の replay として返る
+export type StartSettings = { regions:string[]; allowMega:boolean; allowPrimal:boolean; ambiguity:string; style:string; cooldown:string; timeLimit:number; mode:string; seed:string; pick:string };
+
+// Elo 方式のレーティング (このセッション反映後)
+export type Rating = { player:number; pokemon:number; games:number };
+
+// エンドレスモードの連続正解数
+export type Streak = { current:number; best:number; ended:boolean };
+
 export type SessionState = {
   sessionId: string;
   pokemonId?: number;
   solved?: boolean;
+  timedOut?: boolean;
   answer?: string;
+  result?: QuizResult;
+  streak?: Streak;
+  hintsRevealed?: string[];
+};
+
+// リロード後に再開できるよう最新の sessionId を保存する
+const SESSION_KEY = 'psq.sessionId';
+export const rememberSession = (sessionId:string) => localStorage.setItem(SESSION_KEY, sessionId);
+const forgetSession = () => localStorage.removeItem(SESSION_KEY);
+
+// デイリーチャレンジの 1日1回判定に使うプレイヤー ID (ブラウザごとに生成して保存)
+const PLAYER_KEY = 'psq.playerId';
+export const playerId = () => {
+  let id = localStorage.getItem(PLAYER_KEY);
+  if (!id) {
+    id = crypto.randomUUID();
+    localStorage.setItem(PLAYER_KEY, id);
+  }
+  return id;
 };
 
 type View = 'start' | 'quiz' | 'result';
@@ -16,30 +68,64 @@ export const App: React.FC = () => {
   const [view, setView] = useState<View>('start');
   const [session, setSession] = useState<SessionState | null>(null);
   const [seed, setSeed] = useState(0); // force refresh silhouettes
-  const [config, setConfig] = useState<{regions:string[]; allowMega:boolean; allowPrimal:boolean} | null>(null);
+  const [config, setConfig] = useState<{regions:string[]; allowMega:boolean; allowPrimal:boolean; timeLimit?:number; mode?:string; pick?:string; minDifficulty?:number} | null>(null);
 
-  const startWithConfig = async (c:{regions:string[]; allowMega:boolean; allowPrimal:boolean}) => {
-    // call backend start directly
-    const res = await fetch('/api/quiz/start', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({regions:c.regions, allowMega:c.allowMega, allowPrimal:c.allowPrimal})});
+  const startWithConfig = async (c:{regions:string[]; allowMega:boolean; allowPrimal:boolean; timeLimit?:number; mode?:string; pick?:string; minDifficulty?:number}) => {
+    // call backend start directly (エンドレスのベスト記録はサーバが playerId ごとに管理)
+    await start({regions:c.regions, allowMega:c.allowMega, allowPrimal:c.allowPrimal, timeLimit:c.timeLimit ?? 0, mode:c.mode ?? '', pick:c.pick ?? '', minDifficulty:c.minDifficulty ?? 0});
+  };
+
+  const start = async (body:object) => {
+    const res = await fetch('/api/quiz/start', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({...body, playerId:playerId()})});
+    if (!res.ok) {
+      return;
+    }
     const data = await res.json();
+    rememberSession(data.sessionId);
     setSession({sessionId:data.sessionId});
     setSeed(Date.now());
     setView('quiz');
   };
 
+  // 保存済みセッションがあれば状態を取得して再開
+  useEffect(() => {
+    const saved = localStorage.getItem(SESSION_KEY);
+    if (!saved) {
+      return;
+    }
+    const resume = (id:string): Promise<void> => fetch(`/api/quiz/session/${id}`)
+      .then(r => r.ok ? r.json() : Promise.reject())
+      .then(data => {
+        if (data.nextSessionId) {
+          // エンドレスで正解済み: 応答を受け取る前にリロードされても次の問題から続ける
+          rememberSession(data.nextSessionId);
+          return resume(data.nextSessionId);
+        }
+        if (data.result) {
+          setSession({sessionId:data.sessionId, pokemonId:data.result.pokemonId, answer:data.result.name, result:data.result, solved:data.solved, timedOut:data.timedOut});
+          setView('result');
+        } else {
+          setSession({sessionId:data.sessionId, hintsRevealed:data.hintsRevealed, streak:data.streak});
+          setSeed(Date.now());
+          setView('quiz');
+        }
+      });
+    resume(saved).catch(forgetSession);
+  }, []);
+
   return (
     <div style={{maxWidth: 960, margin: '0 auto', fontFamily:'system-ui'}}>
       {view === 'start' && (
         <StartScreen
           initialConfig={config}
-          onStarted={(sessionId, c)=>{ setConfig(c); setSession({sessionId}); setView('quiz'); setSeed(Date.now()); }}
+          onStarted={(sessionId, c)=>{ rememberSession(sessionId); setConfig(c); setSession({sessionId}); setView('quiz'); setSeed(Date.now()); }}
         />
       )}
       {view === 'quiz' && session && (
-        <QuizScreen key={seed} session={session} onSolved={(p)=>{setSession({...session, ...p}); setView('result');}} onGiveUp={(p)=>{setSession({...session, ...p}); setView('result');}} onAbort={()=>setView('start')} />
+        <QuizScreen key={seed} session={session} onNext={(sessionId, streak)=>{rememberSession(sessionId); setSession({sessionId, streak}); setSeed(Date.now());}} onSolved={(p)=>{rememberSession(p.sessionId); setSession({...session, ...p}); setView('result');}} onGiveUp={(p)=>{rememberSession(p.sessionId); setSession({...session, ...p}); setView('result');}} onAbort={()=>{ forgetSession(); setView('start'); }} />
       )}
       {view === 'result' && session && (
-        <ResultScreen session={session} onNext={()=>{ if(config){ startWithConfig(config); } else { setView('start'); } }} onBack={()=>setView('start')} />
+        <ResultScreen session={session} onNext={()=>{ if(config){ startWithConfig(config); } else { setView('start'); } }} onReplay={session.result?.replay ? ()=>start(session.result!.replay!) : undefined} onBack={()=>{ forgetSession(); setView('start'); }} />
       )}
     </div>
   );
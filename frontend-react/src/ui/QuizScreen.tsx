@@ -1,44 +1,102 @@
 import React, { useEffect, useRef, useState } from 'react';
-import type { SessionState } from './App';
+import { rememberSession, type QuizResult, type SessionState, type Streak } from './App';
 
-type Props = { session: SessionState; onSolved:(p:{pokemonId:number; answer:string})=>void; onGiveUp:(p:{pokemonId:number; answer:string})=>void; onAbort:()=>void };
+type Finished = {pokemonId:number; answer:string; sessionId:string; result?:QuizResult; timedOut?:boolean};
+type Props = { session: SessionState; onSolved:(p:Finished)=>void; onGiveUp:(p:Finished)=>void; onNext:(sessionId:string, streak?:Streak)=>void; onAbort:()=>void };
 
-interface GuessResp { correct:boolean; solved:boolean; retryAfter?:number }
+interface GuessResp { correct:boolean; solved:boolean; retryAfter?:number; retryAfterMs?:number; sessionId?:string; timedOut?:boolean; result?:QuizResult; nextSessionId?:string }
 
-export const QuizScreen: React.FC<Props> = ({session,onSolved,onGiveUp,onAbort}) => {
+export const QuizScreen: React.FC<Props> = ({session,onSolved,onGiveUp,onNext,onAbort}) => {
   const [hint, setHint] = useState<{types:string[]; region:string; firstLetter:string} | null>(null);
-  const [showType, setShowType] = useState(false);
-  const [showRegion, setShowRegion] = useState(false);
-  const [showFirst, setShowFirst] = useState(false);
+  const [showType, setShowType] = useState(!!session.hintsRevealed?.includes('type'));
+  const [showRegion, setShowRegion] = useState(!!session.hintsRevealed?.includes('region'));
+  const [showFirst, setShowFirst] = useState(!!session.hintsRevealed?.includes('first'));
   const [input, setInput] = useState('');
   const inputRef = useRef<HTMLInputElement | null>(null);
   const [candidates, setCandidates] = useState<string[]>([]);
   const [message, setMessage] = useState('');
+  const [warning, setWarning] = useState(false);
   const [loading, setLoading] = useState(false);
-  const retryAfterRef = useRef<number>(0);
+  // 次の回答を受け付ける時刻 (Date.now() 基準)。クールダウンはセッションごとに異なるためサーバの retryAfterMs から決める
+  const retryUntilRef = useRef<number>(0);
+  // トークン方式のセッションでは更新のたびに sessionId が再発行される
+  const sessionIdRef = useRef<string>(session.sessionId);
+  // タイムアタックの残り時間 (ms)。判定はサーバ側で行い、ここでは表示のみ
+  const [timeLeft, setTimeLeft] = useState<number | null>(null);
 
-  const ensureHint = async () => {
-    if (hint) {
+  const showCooldown = (ms:number) => {
+    setMessage(`あと${Math.max(0.1, ms/1000).toFixed(1)}秒待ってから回答してください`);
+    setWarning(true);
+  };
+
+  const finishTimedOut = (result:QuizResult) => {
+    onGiveUp({pokemonId:result.pokemonId, answer:result.name, sessionId:sessionIdRef.current, result, timedOut:true});
+  };
+
+  useEffect(()=>{
+    fetch(`/api/quiz/session/${sessionIdRef.current}`)
+      .then(r => r.ok ? r.json() : null)
+      .then(data => {
+        if (data && data.timeRemainingMs !== undefined) {
+          setTimeLeft(data.timeRemainingMs);
+        }
+      });
+  }, []);
+
+  useEffect(()=>{
+    if (timeLeft === null) {
       return;
     }
-    const res = await fetch(`/api/quiz/hint/${session.sessionId}`);
+    if (timeLeft <= 0) {
+      // 残り時間はサーバに確認してから結果へ
+      fetch(`/api/quiz/session/${sessionIdRef.current}`)
+        .then(r => r.ok ? r.json() : null)
+        .then(data => {
+          if (data?.timedOut && data.result) {
+            finishTimedOut(data.result);
+          } else if (data?.timeRemainingMs) {
+            setTimeLeft(data.timeRemainingMs);
+          }
+        });
+      return;
+    }
+    const t = setTimeout(() => setTimeLeft(v => v === null ? v : Math.max(0, v - 100)), 100);
+    return () => clearTimeout(t);
+  }, [timeLeft]);
+
+  // 開いたヒントの種類をサーバのログに残すため、ボタンごとに取得する
+  const ensureHint = async (kind:'type'|'region'|'first') => {
+    const res = await fetch('/api/quiz/hint', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({sessionId: sessionIdRef.current, kind})});
     if(res.ok){
-      setHint(await res.json());
+      const data = await res.json();
+      if (data.sessionId) {
+        sessionIdRef.current = data.sessionId;
+        rememberSession(data.sessionId);
+      }
+      setHint(data);
     }
   };
   const revealType = async () => {
-    await ensureHint();
+    await ensureHint('type');
     setShowType(true);
   };
   const revealRegion = async () => {
-    await ensureHint();
+    await ensureHint('region');
     setShowRegion(true);
   };
   const revealFirst = async () => {
-    await ensureHint();
+    await ensureHint('first');
     setShowFirst(true);
   };
 
+  useEffect(()=>{
+    // 再開時: 開いていたヒントを再取得 (同じ種類は再カウントされない)
+    const revealed = session.hintsRevealed ?? [];
+    if (revealed.length > 0) {
+      ensureHint(revealed[0] as 'type'|'region'|'first');
+    }
+  }, []);
+
   useEffect(()=>{
     // 自動フォーカス（遷移直後）
     inputRef.current?.focus();
@@ -60,38 +118,46 @@ export const QuizScreen: React.FC<Props> = ({session,onSolved,onGiveUp,onAbort})
     if(!input) {
       return;
     }
-    if (retryAfterRef.current>0){
-      setMessage('回答は5秒空けてください');
+    const wait = retryUntilRef.current - Date.now();
+    if (wait > 0){
+      showCooldown(wait);
       return;
     }
+    setWarning(false);
     setLoading(true);
-    const res = await fetch('/api/quiz/guess', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({sessionId: session.sessionId, answer: input})});
+    const res = await fetch('/api/quiz/guess', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({sessionId: sessionIdRef.current, answer: input})});
     const data: GuessResp = await res.json();
     setLoading(false);
+    if (data.sessionId) {
+      sessionIdRef.current = data.sessionId;
+      rememberSession(data.sessionId);
+    }
+    if (data.timedOut && data.result) {
+      finishTimedOut(data.result);
+      return;
+    }
     if (data.retryAfter) {
-      retryAfterRef.current = data.retryAfter;
-      setMessage('回答は5秒空けてください');
-    }else if (data.correct) {
-      setMessage('正解!');
-      onSolved({pokemonId:0, answer: input});
+      const ms = data.retryAfterMs ?? data.retryAfter * 1000;
+      retryUntilRef.current = Date.now() + ms;
+      showCooldown(ms);
+    }else if (data.solved && data.nextSessionId) {
+      // エンドレス: 正解したらそのまま次のポケモンへ
+      onNext(data.nextSessionId, data.result?.streak);
     }else if (data.solved) {
-      onSolved({pokemonId:0, answer: input});
+      if (data.correct) setMessage('正解!');
+      onSolved({pokemonId:data.result?.pokemonId ?? 0, answer:data.result?.name ?? input, sessionId: sessionIdRef.current, result:data.result});
+    }else if (data.result) {
+      // 正解以外で終了している (エンドレスでの誤答、別タブでのギブアップ済みなど)
+      onGiveUp({pokemonId:data.result.pokemonId, answer:data.result.name, sessionId: sessionIdRef.current, result:data.result});
     }else {
       setMessage('はずれ');
     }
-    // 5秒後にクールダウン解除 (バックエンドと同じ間隔) -> 自動でメッセージクリアはしない
-    if(data.retryAfter){
-      setTimeout(() => {
-        retryAfterRef.current = 0;
-      },
-      data.retryAfter * 1000);
-    }
   };
 
   const giveUp = async () => {
-    const res = await fetch('/api/quiz/giveup', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({sessionId: session.sessionId})});
+    const res = await fetch('/api/quiz/giveup', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({sessionId: sessionIdRef.current})});
     const data = await res.json();
-    onGiveUp({pokemonId:data.pokemonId, answer:data.name});
+    onGiveUp({pokemonId:data.pokemonId, answer:data.name, sessionId: data.sessionId || sessionIdRef.current, result:data});
   };
 
   // dynamic candidate search (Japanese or English)
@@ -123,7 +189,7 @@ export const QuizScreen: React.FC<Props> = ({session,onSolved,onGiveUp,onAbort})
       <h2 style={{fontSize:32, marginBottom:24}}>シルエットを当てよう</h2>
       <div style={{display:'flex', gap:32, alignItems:'flex-start', justifyContent:'center', width:'100%', maxWidth:1000}}>
         <div style={{flex:'0 0 auto', width:400, height:400, background:'#eee', border:'2px solid #ccc', borderRadius:12, display:'flex',alignItems:'center',justifyContent:'center', boxShadow:'0 4px 12px rgba(0,0,0,0.15)'}}>
-          <img src={`/api/quiz/silhouette/${session.sessionId}?ts=${Date.now()}`} alt="silhouette" style={{maxWidth:'100%', maxHeight:'100%'}} />
+          <img src={`/api/quiz/silhouette/${session.sessionId}`} alt="silhouette" style={{maxWidth:'100%', maxHeight:'100%'}} />
         </div>
         <div style={{flex:'1 1 auto', minWidth:260}}>
           <div style={{display:'flex', gap:12, marginBottom:16}}>
@@ -144,7 +210,13 @@ export const QuizScreen: React.FC<Props> = ({session,onSolved,onGiveUp,onAbort})
               {candidates.map(c=> <li key={c} style={{cursor:'pointer', padding:'4px 6px', borderRadius:4}} onClick={()=>setInput(c)}>{c}</li>)}
             </ul>
           )}
-          <div style={{marginTop:16, fontSize:16, color: message==='回答は5秒空けてください' ? 'red':'#222'}}>{message}</div>
+          <div style={{marginTop:16, fontSize:16, color: warning ? 'red':'#222'}}>{message}</div>
+          {session.streak && (
+            <div style={{marginTop:8, fontSize:18}}>連続正解: {session.streak.current} (ベスト {session.streak.best})</div>
+          )}
+          {timeLeft !== null && (
+            <div style={{marginTop:8, fontSize:20, fontWeight:'bold', color: timeLeft < 10000 ? 'red':'#222'}}>残り {(timeLeft/1000).toFixed(1)} 秒</div>
+          )}
         </div>
         {(showType || showRegion || showFirst) && (
           <div style={{flex:'0 0 220px', background:'#fafafa', border:'1px solid #ddd', borderRadius:12, padding:16, boxShadow:'0 2px 8px rgba(0,0,0,0.1)'}}>
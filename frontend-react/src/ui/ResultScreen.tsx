@@ -1,7 +1,17 @@
-import React, { useEffect } from 'react';
+import React, { useEffect, useState } from 'react';
 import type { SessionState } from './App';
 
-export const ResultScreen: React.FC<{session:SessionState; onNext:()=>void; onBack:()=>void}> = ({session,onNext,onBack}) => {
+type DailyStats = { date:string; players:number; finished:number; solved:number; solveRate:number; avgGuesses:number };
+
+export const ResultScreen: React.FC<{session:SessionState; onNext:()=>void; onBack:()=>void; onReplay?:()=>void}> = ({session,onNext,onBack,onReplay}) => {
+  const [daily, setDaily] = useState<DailyStats | null>(null);
+  useEffect(() => {
+    const date = session.result?.daily;
+    if (!date) {
+      return;
+    }
+    fetch(`/api/quiz/daily/stats?date=${date}`).then(r => r.ok ? r.json() : null).then(setDaily);
+  }, [session.result?.daily]);
   useEffect(() => {
     const handler = (keyEvent: KeyboardEvent) => {
       if (keyEvent.key === 'Enter') {
@@ -20,12 +30,34 @@ export const ResultScreen: React.FC<{session:SessionState; onNext:()=>void; onBa
       <h2 style={{fontSize:32, marginBottom:24}}>結果</h2>
       <div style={{display:'flex', flexDirection:'column', alignItems:'center', gap:24}}>
         <div style={{width:420,height:420, background:'#fff', display:'flex',alignItems:'center',justifyContent:'center', border:'2px solid #ccc', borderRadius:16, boxShadow:'0 4px 14px rgba(0,0,0,0.15)'}}>
-          <img src={`/api/quiz/artwork/${session.sessionId}?ts=${Date.now()}`} alt={session.answer} style={{maxWidth:'100%', maxHeight:'100%'}} />
+          <img src={`/api/quiz/artwork/${session.sessionId}`} alt={session.answer} style={{maxWidth:'100%', maxHeight:'100%'}} />
         </div>
+        {session.timedOut && <div style={{fontSize:20, color:'red'}}>時間切れ</div>}
         <div style={{fontSize:28}}>答え: <strong>{session.answer}</strong></div>
+        {daily && (
+          <div style={{fontSize:16, color:'#555'}}>今日のチャレンジ ({daily.date}): 正解率 {Math.round(daily.solveRate*100)}% / 平均回答数 {daily.avgGuesses.toFixed(1)} ({daily.finished}人)</div>
+        )}
+        {session.result?.ranked && (
+          <div style={{fontSize:20}}>スコア: <strong>{session.result.score}</strong> (難易度 {Math.round(session.result.difficulty*100)})</div>
+        )}
+        {session.result?.rating && (
+          <div style={{fontSize:16, color:'#555'}}>レーティング: <strong>{session.result.rating.player}</strong> (ポケモン {session.result.rating.pokemon})</div>
+        )}
+        {session.result?.streak && (
+          <div style={{fontSize:20}}>連続正解: {session.result.streak.current} (ベスト {session.result.streak.best})</div>
+        )}
+        {session.result && (
+          <div style={{maxWidth:420, textAlign:'center', display:'flex', flexDirection:'column', gap:8}}>
+            <div style={{fontSize:16, color:'#555'}}>{session.result.nameEn}{session.result.genus && ` / ${session.result.genus}`}</div>
+            {session.result.dexEntry && <div style={{fontSize:15, lineHeight:1.6}}>{session.result.dexEntry}</div>}
+          </div>
+        )}
       </div>
       <div style={{display:'flex', gap:20, marginTop:32}}>
         <button style={navBtn} onClick={onBack}>スタート画面 (Esc)</button>
+        {onReplay && (
+          <button style={navBtn} onClick={onReplay} title={`シード: ${session.result?.seed}`}>同じ問題に再挑戦</button>
+        )}
         <button style={primaryBtn} onClick={onNext}>次の問題 (Enter)</button>
       </div>
     </div>
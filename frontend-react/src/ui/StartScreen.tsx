@@ -1,4 +1,5 @@
 import React, { useEffect, useState } from 'react';
+import { playerId } from './App';
 
 const regions = [
   {key:'kanto', label:'カントー'}, {key:'johto', label:'ジョウト'}, {key:'hoenn', label:'ホウエン'},
@@ -6,24 +7,65 @@ const regions = [
   {key:'alola', label:'アローラ'}, {key:'galar', label:'ガラル'}, {key:'paldea', label:'パルデア'}
 ];
 
-type Config = { regions:string[]; allowMega:boolean; allowPrimal:boolean };
+type Config = { regions:string[]; allowMega:boolean; allowPrimal:boolean; timeLimit?:number; mode?:string; pick?:string; minDifficulty?:number };
+
+// 「難しいポケモンのみ」の難易度下限 (quiz.HardDifficulty)
+const HARD_DIFFICULTY = 0.6;
+
+// 出題ポケモンの選び方 (startRequest.pick)
+const picks = [
+  {key:'uniform', label:'ランダム'}, {key:'species', label:'種族ごとに均等'}, {key:'region', label:'地方ごとに均等'},
+  {key:'difficulty', label:'難しいポケモン多め'}, {key:'missed', label:'間違えたポケモン多め'},
+  {key:'adaptive', label:'実力に合わせる'}
+];
+
+// タイムアタックの制限時間 (秒)
+const TIME_ATTACK_SECONDS = 30;
 export const StartScreen: React.FC<{onStarted:(sessionId:string, config:Config)=>void; initialConfig?:Config | null}> = ({onStarted, initialConfig}) => {
   const [selected, setSelected] = useState<string[]>(initialConfig?.regions ?? regions.map(r=>r.key));
   const [allowMega, setAllowMega] = useState(initialConfig?.allowMega ?? false);
   const [allowPrimal, setAllowPrimal] = useState(initialConfig?.allowPrimal ?? false);
+  const [timeAttack, setTimeAttack] = useState(!!initialConfig?.timeLimit);
+  const [endless, setEndless] = useState(initialConfig?.mode === 'endless');
+  const [pick, setPick] = useState(initialConfig?.pick ?? 'uniform');
+  const [hardOnly, setHardOnly] = useState(!!initialConfig?.minDifficulty);
   useEffect(()=>{
     if (initialConfig) {
       setSelected(initialConfig.regions);
       setAllowMega(initialConfig.allowMega);
       setAllowPrimal(initialConfig.allowPrimal);
+      setTimeAttack(!!initialConfig.timeLimit);
+      setEndless(initialConfig.mode === 'endless');
+      setPick(initialConfig.pick ?? 'uniform');
+      setHardOnly(!!initialConfig.minDifficulty);
     }
   }, [initialConfig]);
   const toggle = (k:string) => setSelected(s => s.includes(k) ? s.filter(x=>x!==k) : [...s,k]);
 
+  const [dailyMessage, setDailyMessage] = useState(''); // デイリー・開始エラーの表示
+
+  // デイリーチャレンジ: 全員同じポケモン、1日1回まで
+  const startDaily = async () => {
+    const res = await fetch('/api/quiz/daily/start', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({playerId:playerId()})});
+    if (res.status === 409) {
+      setDailyMessage('今日のチャレンジは挑戦済みです。また明日!');
+      return;
+    }
+    const data = await res.json();
+    onStarted(data.sessionId, {regions:selected, allowMega, allowPrimal, timeLimit: timeAttack ? TIME_ATTACK_SECONDS : 0, mode: endless ? 'endless' : '', pick, minDifficulty: hardOnly ? HARD_DIFFICULTY : 0});
+  };
+
   const start = async () => {
-    const res = await fetch('/api/quiz/start', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({regions:selected, allowMega:allowMega, allowPrimal:allowPrimal})});
+    const timeLimit = timeAttack ? TIME_ATTACK_SECONDS : 0;
+    const mode = endless ? 'endless' : '';
+    const minDifficulty = hardOnly ? HARD_DIFFICULTY : 0;
+    const res = await fetch('/api/quiz/start', {method:'POST', headers:{'Content-Type':'application/json'}, body: JSON.stringify({regions:selected, allowMega:allowMega, allowPrimal:allowPrimal, timeLimit, mode, pick, minDifficulty, playerId:playerId()})});
+    if (!res.ok) {
+      setDailyMessage((await res.json()).error ?? '開始できませんでした');
+      return;
+    }
     const data = await res.json();
-    onStarted(data.sessionId, {regions:selected, allowMega, allowPrimal});
+    onStarted(data.sessionId, {regions:selected, allowMega, allowPrimal, timeLimit, mode, pick, minDifficulty});
   };
 
   return (
@@ -65,8 +107,33 @@ export const StartScreen: React.FC<{onStarted:(sessionId:string, config:Config)=
             </label>
           </div>
         </section>
+        <section style={{marginBottom:36}}>
+          <h2 style={{fontSize:24, margin:'0 0 16px'}}>出題方法</h2>
+          <select value={pick} onChange={e=>setPick(e.target.value)} style={{fontSize:16, padding:'8px 12px', borderRadius:8}}>
+            {picks.map(p => <option key={p.key} value={p.key}>{p.label}</option>)}
+          </select>
+          <label style={{...checkLabelStyle, marginLeft:24}}>
+            <input type="checkbox" checked={hardOnly} onChange={e=>setHardOnly(e.target.checked)} />
+            <span>難しいポケモンのみ</span>
+          </label>
+        </section>
+        <section style={{marginBottom:36}}>
+          <h2 style={{fontSize:24, margin:'0 0 16px'}}>モード</h2>
+          <div style={{display:'flex', gap:32, flexWrap:'wrap'}}>
+            <label style={checkLabelStyle}>
+              <input type="checkbox" checked={timeAttack} onChange={e=>setTimeAttack(e.target.checked)} />
+              <span>タイムアタック ({TIME_ATTACK_SECONDS}秒)</span>
+            </label>
+            <label style={checkLabelStyle}>
+              <input type="checkbox" checked={endless} onChange={e=>setEndless(e.target.checked)} />
+              <span>エンドレス (1問でも間違えたら終了)</span>
+            </label>
+          </div>
+        </section>
         <div style={{textAlign:'center'}}>
           <button onClick={start} style={startBtnStyle}>スタート</button>
+          <button onClick={startDaily} style={{...startBtnStyle, background:'#059669', marginLeft:16}}>今日のチャレンジ</button>
+          {dailyMessage && <div style={{marginTop:12, color:'#c0392b'}}>{dailyMessage}</div>}
         </div>
       </div>
     </div>